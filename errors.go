@@ -0,0 +1,184 @@
+package netaddr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress means that an IP address could not be parsed
+	ErrInvalidAddress = errors.New("invalid IP address")
+	// ErrInvalidPrefixLength means that a prefix length is missing, is not a
+	// number or is too long for the address family
+	ErrInvalidPrefixLength = errors.New("invalid prefix length")
+	// ErrHostBitsSet means that a network address has ones in the host part
+	ErrHostBitsSet = errors.New("host bits set")
+	// ErrFamilyMismatch means that IPv4 and IPv6 values were mixed
+	ErrFamilyMismatch = errors.New("address family mismatch")
+	// ErrNonContiguousMask means that a netmask has zeros between its ones
+	ErrNonContiguousMask = errors.New("non-contiguous netmask")
+	// ErrTooManyNetworks means that a result would have more networks than
+	// the caller allowed
+	ErrTooManyNetworks = errors.New("too many networks")
+	// ErrAmbiguousAddress means that an address uses a legacy form, like
+	// octal or hex parts, which different parsers read differently
+	ErrAmbiguousAddress = errors.New("ambiguous IP address")
+	// ErrInvalidZone means that an IPv6 zone is empty or was given with an
+	// IPv4 address
+	ErrInvalidZone = errors.New("invalid IPv6 zone")
+	// ErrInvalidPort means that a port is missing, is not a number or is
+	// larger than 65535
+	ErrInvalidPort = errors.New("invalid port")
+	// ErrInvalidRange means that a range is malformed or its first address
+	// comes after its last address
+	ErrInvalidRange = errors.New("invalid IP range")
+)
+
+// ParseError describes a string which could not be parsed. Use errors.Is to
+// check which of the Err* errors caused it.
+type ParseError struct {
+	// Input is the string which failed to parse
+	Input string
+	// Pos is the byte offset into Input where the problem was found
+	Pos int
+	// Err is the reason, usually one of the Err* errors
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %q at position %d", e.Err, e.Input, e.Pos)
+}
+
+// Unwrap returns the reason for the error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// rebaseError makes a *ParseError for part of a string relative to the whole
+// string given the offset of the part. Other errors are returned as is.
+func rebaseError(err error, input string, offset int) error {
+	perr, ok := err.(*ParseError)
+	if !ok {
+		return err
+	}
+	return &ParseError{Input: input, Pos: offset + perr.Pos, Err: perr.Err}
+}
+
+// checkFamily returns an error wrapping ErrFamilyMismatch unless the family is
+// net.IPv4len or net.IPv6len
+func checkFamily(family int) error {
+	if family != net.IPv4len && family != net.IPv6len {
+		return fmt.Errorf("bad address family %d: %w", family, ErrFamilyMismatch)
+	}
+	return nil
+}
+
+// invalidAddressPos makes a best effort to find the offset of the first
+// problem in an address which failed to parse.
+func invalidAddressPos(address string) int {
+	if strings.Contains(address, ":") {
+		start := 0
+		for i, c := range address {
+			switch {
+			case c == ':':
+				start = i + 1
+			case c == '.':
+			case strings.ContainsRune("0123456789abcdefABCDEF", c):
+				if i-start >= 4 && !strings.Contains(address[start:], ".") {
+					return start
+				}
+			default:
+				return i
+			}
+		}
+		return 0
+	}
+
+	start := 0
+	for i, part := range strings.Split(address, ".") {
+		if i == 4 {
+			return start - 1
+		}
+		if part == "" || (len(part) > 1 && part[0] == '0') {
+			return start
+		}
+		value := 0
+		for j, c := range part {
+			if c < '0' || c > '9' {
+				return start + j
+			}
+			value = value*10 + int(c-'0')
+			if value > 255 {
+				return start
+			}
+		}
+		start += len(part) + 1
+	}
+	return len(address)
+}
+
+// hostBitsPos returns the offset in address of the first IPv4 octet or IPv6
+// group of ip which has bits set outside of mask. The address must be the
+// string which ip was parsed from.
+func hostBitsPos(address string, ip net.IP, mask net.IPMask) int {
+	i := 0
+	for i < len(ip) && i < len(mask) && ip[i]&^mask[i] == 0 {
+		i++
+	}
+	starts := byteStarts(address, len(ip))
+	if i >= len(starts) {
+		return 0
+	}
+	return starts[i]
+}
+
+// byteStarts returns the offset in address of the text for each byte of the
+// IP of the given size which it holds. Bytes elided by "::" get the offset of
+// the "::". It returns nil if the address doesn't have the expected size.
+func byteStarts(address string, size int) []int {
+	// parts returns the offsets for the bytes of a run of groups
+	parts := func(str string, offset int) (starts []int) {
+		if str == "" {
+			return
+		}
+		sep := ":"
+		if size == net.IPv4len {
+			sep = "."
+		}
+		for _, part := range strings.Split(str, sep) {
+			if strings.Contains(part, ".") {
+				for _, octet := range strings.Split(part, ".") {
+					starts = append(starts, offset)
+					offset += len(octet) + 1
+				}
+				continue
+			}
+			starts = append(starts, offset)
+			if size == net.IPv6len {
+				starts = append(starts, offset)
+			}
+			offset += len(part) + 1
+		}
+		return
+	}
+
+	elided := strings.Index(address, "::")
+	if elided < 0 {
+		starts := parts(address, 0)
+		if len(starts) != size {
+			return nil
+		}
+		return starts
+	}
+	head, tail := parts(address[:elided], 0), parts(address[elided+2:], elided+2)
+	if len(head)+len(tail) > size {
+		return nil
+	}
+	starts := head
+	for len(starts)+len(tail) < size {
+		starts = append(starts, elided)
+	}
+	return append(starts, tail...)
+}
@@ -0,0 +1,82 @@
+package netaddr
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	err := &ParseError{Input: "10.0.0.0/33", Pos: 9, Err: ErrInvalidPrefixLength}
+	assert.Equal(t, `invalid prefix length: "10.0.0.0/33" at position 9`, err.Error())
+	assert.True(t, errors.Is(err, ErrInvalidPrefixLength))
+	assert.False(t, errors.Is(err, ErrInvalidAddress))
+}
+
+func TestInvalidAddressPos(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+	}{
+		{"", 0},
+		{"10.0.0.256", 7},
+		{"10.0.300.1", 5},
+		{"10.0.x.1", 5},
+		{"10.0.0.1x", 8},
+		{"10.0..1", 5},
+		{"10.0.0", 6},
+		{"10.0.0.1.2", 8},
+		{"010.0.0.1", 0},
+		{"bogus", 0},
+		{"2001:db8::g", 10},
+		{"2001:db8:12345::", 9},
+		{"2001:db8:::1", 0},
+		{"::ffff:1.2.3.4.5", 0},
+	} {
+		assert.Equal(t, tc.pos, invalidAddressPos(tc.in), tc.in)
+	}
+}
+
+func TestCheckFamily(t *testing.T) {
+	assert.Nil(t, checkFamily(net.IPv4len))
+	assert.Nil(t, checkFamily(net.IPv6len))
+	for _, family := range []int{0, 8, -4} {
+		err := checkFamily(family)
+		assert.True(t, errors.Is(err, ErrFamilyMismatch))
+	}
+}
+
+func TestHostBitsPos(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		len int
+		pos int
+	}{
+		{"10.0.0.1", 24, 7},
+		{"10.0.1.0", 16, 5},
+		{"10.1.0.0", 8, 3},
+		{"10.0.0.0", 0, 0},
+		{"192.168.100.0", 16, 8},
+		{"2001:db8::1", 64, 10},
+		{"2001:db8:0:0:1::", 64, 13},
+		{"2001:db8:1::", 32, 9},
+		{"2001:db8:0:0:1::", 48, 13},
+		{"::ffff:10.0.0.1", 120, 14},
+		{"::ffff:10.0.1.0", 112, 12},
+		{"::1", 64, 2},
+	} {
+		ip := ParseIP(tc.in)
+		mask := net.CIDRMask(tc.len, 8*len(ip))
+		assert.Equal(t, tc.pos, hostBitsPos(tc.in, ip, mask), tc.in)
+	}
+}
+
+func TestByteStarts(t *testing.T) {
+	assert.Equal(t, []int{0, 3, 5, 7}, byteStarts("10.0.0.1", net.IPv4len))
+	assert.Equal(t, make([]int, net.IPv6len), byteStarts("::", net.IPv6len))
+	assert.Equal(t, []int{0, 0, 5, 5, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 10, 10}, byteStarts("2001:db8::1", net.IPv6len))
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 2, 7, 10, 12, 14}, byteStarts("::ffff:10.0.0.1", net.IPv6len))
+	assert.Nil(t, byteStarts("10.0.0", net.IPv4len))
+}
@@ -0,0 +1,119 @@
+package netaddr
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPv6Format selects how FormatIP writes IPv6 addresses
+type IPv6Format int
+
+const (
+	// IPv6Canonical is the RFC 5952 form, e.g. "2001:db8::1". As RFC 5952
+	// recommends, IPv4-mapped addresses are written like "::ffff:192.0.2.1".
+	IPv6Canonical IPv6Format = iota
+	// IPv6Exploded writes all eight groups padded with zeros, e.g.
+	// "2001:0db8:0000:0000:0000:0000:0000:0001"
+	IPv6Exploded
+	// IPv6EmbeddedIPv4 writes the last 32 bits in dotted decimal, e.g.
+	// "64:ff9b::192.0.2.1"
+	IPv6EmbeddedIPv4
+)
+
+// FormatOptions controls the output of FormatIP, FormatNet and FormatRange.
+// The zero value gives canonical, lowercase output.
+type FormatOptions struct {
+	// IPv6 selects the form of IPv6 addresses. IPv4 addresses are always
+	// written in dotted decimal.
+	IPv6 IPv6Format
+	// Uppercase writes IPv6 hex digits in uppercase
+	Uppercase bool
+}
+
+// ipv4MappedPrefix is the first 12 bytes of an IPv4-mapped IPv6 address
+var ipv4MappedPrefix = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
+
+// FormatIP returns the given IP as a string according to opts. It returns the
+// same as ip.String() if the IP is neither 4 nor 16 bytes.
+func FormatIP(ip net.IP, opts FormatOptions) string {
+	if len(ip) != net.IPv6len {
+		return ip.String()
+	}
+
+	groups := make([]uint16, 8)
+	for i := range groups {
+		groups[i] = uint16(ip[2*i])<<8 | uint16(ip[2*i+1])
+	}
+
+	var str string
+	switch {
+	case opts.IPv6 == IPv6Exploded:
+		exploded := make([]string, len(groups))
+		for i, g := range groups {
+			exploded[i] = fmt.Sprintf("%04x", g)
+		}
+		str = strings.Join(exploded, ":")
+	case opts.IPv6 == IPv6EmbeddedIPv4 || bytes.Equal(ip[:12], ipv4MappedPrefix):
+		str = formatGroups(groups[:6])
+		if !strings.HasSuffix(str, "::") {
+			str += ":"
+		}
+		str += net.IP(ip[12:]).String()
+	default:
+		str = formatGroups(groups)
+	}
+
+	if opts.Uppercase {
+		return strings.ToUpper(str)
+	}
+	return str
+}
+
+// FormatNet returns the given network in CIDR notation with the IP formatted
+// according to opts
+func FormatNet(n *net.IPNet, opts FormatOptions) string {
+	ones, _ := n.Mask.Size()
+	return FormatIP(n.IP, opts) + "/" + strconv.Itoa(ones)
+}
+
+// FormatRange returns the given range as "first-last", which ParseIPRange
+// accepts, with the IPs formatted according to opts
+func FormatRange(r *IPRange, opts FormatOptions) string {
+	return FormatIP(r.First, opts) + "-" + FormatIP(r.Last, opts)
+}
+
+// formatGroups writes the given 16 bit groups in hex with the longest run of
+// two or more zero groups replaced by "::" as described in RFC 5952. If there
+// are two runs of the same length, the first is replaced.
+func formatGroups(groups []uint16) string {
+	bestStart, bestLen := -1, 1
+	for i := 0; i < len(groups); {
+		if groups[i] != 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < len(groups) && groups[j] == 0 {
+			j++
+		}
+		if j-i > bestLen {
+			bestStart, bestLen = i, j-i
+		}
+		i = j
+	}
+
+	hex := func(groups []uint16) string {
+		strs := make([]string, len(groups))
+		for i, g := range groups {
+			strs[i] = strconv.FormatUint(uint64(g), 16)
+		}
+		return strings.Join(strs, ":")
+	}
+	if bestStart < 0 {
+		return hex(groups)
+	}
+	return hex(groups[:bestStart]) + "::" + hex(groups[bestStart+bestLen:])
+}
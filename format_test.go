@@ -0,0 +1,83 @@
+package netaddr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatIPCanonical(t *testing.T) {
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"10.0.0.1", "10.0.0.1"},
+		{"::", "::"},
+		{"::1", "::1"},
+		{"1::", "1::"},
+		{"2001:0DB8::0001", "2001:db8::1"},
+		{"2001:db8:0:0:1:0:0:1", "2001:db8::1:0:0:1"},
+		{"2001:0:0:1:0:0:0:1", "2001:0:0:1::1"},
+		{"2001:db8:0:1:1:1:1:1", "2001:db8:0:1:1:1:1:1"},
+		{"1:2:3:4:5:6:7:8", "1:2:3:4:5:6:7:8"},
+		{"::ffff:192.0.2.1", "::ffff:192.0.2.1"},
+		{"64:ff9b::c000:201", "64:ff9b::c000:201"},
+	} {
+		assert.Equal(t, tc.result, FormatIP(ParseIP(tc.in), FormatOptions{}), tc.in)
+	}
+	assert.Equal(t, "::ffff:192.0.2.1", FormatIP(net.ParseIP("192.0.2.1"), FormatOptions{}))
+	assert.Equal(t, "<nil>", FormatIP(nil, FormatOptions{}))
+}
+
+func TestFormatIPExploded(t *testing.T) {
+	opts := FormatOptions{IPv6: IPv6Exploded}
+	assert.Equal(t, "2001:0db8:0000:0000:0000:0000:0000:0001", FormatIP(ParseIP("2001:db8::1"), opts))
+	assert.Equal(t, "0000:0000:0000:0000:0000:0000:0000:0000", FormatIP(ParseIP("::"), opts))
+	assert.Equal(t, "0000:0000:0000:0000:0000:ffff:c000:0201", FormatIP(ParseIP("::ffff:192.0.2.1"), opts))
+	assert.Equal(t, "10.0.0.1", FormatIP(ParseIP("10.0.0.1"), opts))
+
+	opts.Uppercase = true
+	assert.Equal(t, "2001:0DB8:0000:0000:0000:0000:0000:00AB", FormatIP(ParseIP("2001:db8::ab"), opts))
+}
+
+func TestFormatIPEmbedded(t *testing.T) {
+	opts := FormatOptions{IPv6: IPv6EmbeddedIPv4}
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"::ffff:1.2.3.4", "::ffff:1.2.3.4"},
+		{"64:ff9b::102:304", "64:ff9b::1.2.3.4"},
+		{"::102:304", "::1.2.3.4"},
+		{"1:2:3:4:5:6:102:304", "1:2:3:4:5:6:1.2.3.4"},
+		{"1:2:3:4:5:0:102:304", "1:2:3:4:5:0:1.2.3.4"},
+		{"1:2:3:4:0:0:102:304", "1:2:3:4::1.2.3.4"},
+		{"1::6:102:304", "1::6:1.2.3.4"},
+	} {
+		assert.Equal(t, tc.result, FormatIP(ParseIP(tc.in), opts), tc.in)
+	}
+
+	opts.Uppercase = true
+	assert.Equal(t, "64:FF9B::1.2.3.4", FormatIP(ParseIP("64:ff9b::102:304"), opts))
+}
+
+func TestFormatIPCanonicalUppercase(t *testing.T) {
+	assert.Equal(t, "2001:DB8::AB", FormatIP(ParseIP("2001:db8::ab"), FormatOptions{Uppercase: true}))
+}
+
+func TestFormatNet(t *testing.T) {
+	assert.Equal(t, "10.0.0.0/8", FormatNet(parse("10.0.0.0/8"), FormatOptions{}))
+	assert.Equal(t, "2001:db8::/32", FormatNet(parse("2001:db8::/32"), FormatOptions{}))
+	assert.Equal(t, "2001:0db8:0000:0000:0000:0000:0000:0000/32", FormatNet(parse("2001:db8::/32"), FormatOptions{IPv6: IPv6Exploded}))
+	assert.Equal(t, "64:ff9b::0.0.0.0/96", FormatNet(parse("64:ff9b::/96"), FormatOptions{IPv6: IPv6EmbeddedIPv4}))
+}
+
+func TestFormatRange(t *testing.T) {
+	r := rng("2001:db8::1-2001:db8::ff")
+	assert.Equal(t, "2001:db8::1-2001:db8::ff", FormatRange(r, FormatOptions{}))
+	assert.Equal(t, "2001:0DB8:0000:0000:0000:0000:0000:0001-2001:0DB8:0000:0000:0000:0000:0000:00FF", FormatRange(r, FormatOptions{IPv6: IPv6Exploded, Uppercase: true}))
+
+	text := FormatRange(r, FormatOptions{IPv6: IPv6Exploded})
+	parsed, err := ParseIPRange(text)
+	assert.Nil(t, err)
+	assert.Equal(t, r, parsed)
+}
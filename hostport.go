@@ -0,0 +1,70 @@
+package netaddr
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseIPPort parses an IP and port like "10.0.0.1:80" or "[2001:db8::1]:443"
+// and returns them along with the zone, if any, as in "[fe80::1%eth0]:22".
+// IPv4 addresses are parsed as 4 byte addresses like ParseIP. IPv6 addresses
+// must be in brackets and IPv4 addresses must not be. Errors are returned as
+// *ParseError which wraps ErrInvalidAddress, ErrInvalidZone or ErrInvalidPort.
+func ParseIPPort(str string) (net.IP, uint16, string, error) {
+	fail := func(pos int, err error) (net.IP, uint16, string, error) {
+		return nil, 0, "", &ParseError{Input: str, Pos: pos, Err: err}
+	}
+
+	var host string
+	var colon, offset int
+	bracketed := strings.HasPrefix(str, "[")
+	if bracketed {
+		end := strings.Index(str, "]")
+		if end < 0 {
+			return fail(len(str), ErrInvalidAddress)
+		}
+		host, colon, offset = str[1:end], end+1, 1
+		if colon < len(str) && str[colon] != ':' {
+			return fail(colon, ErrInvalidPort)
+		}
+	} else {
+		colon = strings.Index(str, ":")
+		if colon < 0 {
+			colon = len(str)
+		}
+		host = str[:colon]
+		if strings.Count(str, ":") > 1 {
+			// IPv6 without brackets, which is ambiguous with a port
+			return fail(0, ErrInvalidAddress)
+		}
+	}
+
+	ip, zone, err := ParseIPZone(host)
+	if err != nil {
+		return nil, 0, "", rebaseError(err, str, offset)
+	}
+	if bracketed != (len(ip) == net.IPv6len) {
+		return fail(0, ErrInvalidAddress)
+	}
+
+	if colon >= len(str) {
+		return fail(len(str), ErrInvalidPort)
+	}
+	portStr := str[colon+1:]
+	if portStr == "" || portStr[0] == '+' || portStr[0] == '-' {
+		return fail(colon+1, ErrInvalidPort)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fail(colon+1, ErrInvalidPort)
+	}
+	return ip, uint16(port), zone, nil
+}
+
+// FormatIPPort returns the IP, zone and port in the form accepted by
+// ParseIPPort, with brackets around IPv6 addresses. The zone is left out if it
+// is empty.
+func FormatIPPort(ip net.IP, port uint16, zone string) string {
+	return net.JoinHostPort(FormatIPZone(ip, zone), strconv.Itoa(int(port)))
+}
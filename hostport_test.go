@@ -0,0 +1,77 @@
+package netaddr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPPort(t *testing.T) {
+	for _, tc := range []struct {
+		str  string
+		ip   net.IP
+		port uint16
+		zone string
+	}{
+		{"10.0.0.1:80", IPv4(10, 0, 0, 1), 80, ""},
+		{"0.0.0.0:0", IPv4(0, 0, 0, 0), 0, ""},
+		{"10.0.0.1:65535", IPv4(10, 0, 0, 1), 65535, ""},
+		{"[2001:db8::1]:443", net.ParseIP("2001:db8::1"), 443, ""},
+		{"[fe80::1%eth0]:22", net.ParseIP("fe80::1"), 22, "eth0"},
+	} {
+		ip, port, zone, err := ParseIPPort(tc.str)
+		if assert.Nil(t, err, tc.str) {
+			assert.Equal(t, tc.ip, ip, tc.str)
+			assert.Equal(t, tc.port, port, tc.str)
+			assert.Equal(t, tc.zone, zone, tc.str)
+			assert.Equal(t, tc.str, FormatIPPort(ip, port, zone))
+		}
+	}
+}
+
+func TestParseIPPortMapped(t *testing.T) {
+	ip, port, zone, err := ParseIPPort("[::ffff:10.0.0.1]:443")
+	assert.Nil(t, err)
+	assert.Equal(t, net.ParseIP("::ffff:10.0.0.1"), ip)
+	assert.Equal(t, uint16(443), port)
+	assert.Equal(t, "", zone)
+}
+
+func TestParseIPPortErrors(t *testing.T) {
+	for _, tc := range []struct {
+		str string
+		pos int
+		err error
+	}{
+		{"", 0, ErrInvalidAddress},
+		{"10.0.0.1", 8, ErrInvalidPort},
+		{"10.0.0.1:", 9, ErrInvalidPort},
+		{"10.0.0.1:http", 9, ErrInvalidPort},
+		{"10.0.0.1:65536", 9, ErrInvalidPort},
+		{"10.0.0.1:+80", 9, ErrInvalidPort},
+		{"10.0.0.1:-80", 9, ErrInvalidPort},
+		{"10.0.0.256:80", 7, ErrInvalidAddress},
+		{"[10.0.0.1]:80", 0, ErrInvalidAddress},
+		{"2001:db8::1:80", 0, ErrInvalidAddress},
+		{"[2001:db8::1]", 13, ErrInvalidPort},
+		{"[2001:db8::1]80", 13, ErrInvalidPort},
+		{"[2001:db8::1:80", 15, ErrInvalidAddress},
+		{"[2001:db8::g]:80", 11, ErrInvalidAddress},
+		{"[fe80::1%]:80", 8, ErrInvalidZone},
+		{"10.0.0.1%eth0:80", 8, ErrInvalidZone},
+	} {
+		ip, port, zone, err := ParseIPPort(tc.str)
+		assert.Nil(t, ip, tc.str)
+		assert.Equal(t, uint16(0), port, tc.str)
+		assert.Equal(t, "", zone, tc.str)
+		assert.Equal(t, &ParseError{Input: tc.str, Pos: tc.pos, Err: tc.err}, err, tc.str)
+	}
+}
+
+func TestFormatIPPort(t *testing.T) {
+	assert.Equal(t, "10.0.0.1:80", FormatIPPort(IPv4(10, 0, 0, 1), 80, ""))
+	assert.Equal(t, "10.0.0.1:80", FormatIPPort(net.ParseIP("10.0.0.1"), 80, ""))
+	assert.Equal(t, "[2001:db8::1]:8080", FormatIPPort(net.ParseIP("2001:db8::1"), 8080, ""))
+	assert.Equal(t, "[fe80::1%eth0]:22", FormatIPPort(net.ParseIP("fe80::1"), 22, "eth0"))
+}
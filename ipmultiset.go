@@ -0,0 +1,135 @@
+package netaddr
+
+import (
+	"net"
+	"sort"
+)
+
+// IPMultiSet is a set of IP addresses where each address remembers which
+// sources inserted it. It answers questions like "how many sources list this
+// address" that a plain IPSet cannot. Inserting the same address more than
+// once from the same source counts only once.
+type IPMultiSet struct {
+	sources map[string]*IPSet
+}
+
+// source returns the set for the given source ID, creating it if needed
+func (m *IPMultiSet) source(id string) *IPSet {
+	if m.sources == nil {
+		m.sources = map[string]*IPSet{}
+	}
+	s, ok := m.sources[id]
+	if !ok {
+		s = &IPSet{}
+		m.sources[id] = s
+	}
+	return s
+}
+
+// InsertNet records that the given source lists the entire given IP network
+func (m *IPMultiSet) InsertNet(id string, net *net.IPNet) {
+	if net == nil {
+		return
+	}
+	m.source(id).InsertNet(net)
+}
+
+// Insert records that the given source lists the given IP
+func (m *IPMultiSet) Insert(id string, ip net.IP) {
+	m.InsertNet(id, ipToNet(ip))
+}
+
+// RemoveNet ensures that the given source no longer lists any of the IPs in
+// the given network. A source is forgotten once it lists no IPs.
+func (m *IPMultiSet) RemoveNet(id string, net *net.IPNet) {
+	if m == nil || m.sources[id] == nil || net == nil {
+		return
+	}
+	s := m.sources[id]
+	s.RemoveNet(net)
+	if s.tree == nil {
+		delete(m.sources, id)
+	}
+}
+
+// Remove ensures that the given source no longer lists the given IP
+func (m *IPMultiSet) Remove(id string, ip net.IP) {
+	m.RemoveNet(id, ipToNet(ip))
+}
+
+// Count returns the number of sources that list the given IP
+func (m *IPMultiSet) Count(ip net.IP) int {
+	if m == nil {
+		return 0
+	}
+	count := 0
+	for _, s := range m.sources {
+		if s.Contains(ip) {
+			count++
+		}
+	}
+	return count
+}
+
+// Sources returns the sorted IDs of the sources that list the given IP
+func (m *IPMultiSet) Sources(ip net.IP) []string {
+	ids := []string{}
+	if m == nil {
+		return ids
+	}
+	for id, s := range m.sources {
+		if s.Contains(ip) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SourceIDs returns the sorted IDs of all sources that list at least one IP
+func (m *IPMultiSet) SourceIDs() []string {
+	ids := []string{}
+	if m == nil {
+		return ids
+	}
+	for id := range m.sources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Source returns a copy of the IPs listed by the given source
+func (m *IPMultiSet) Source(id string) *IPSet {
+	if m == nil || m.sources[id] == nil {
+		return &IPSet{}
+	}
+	return m.sources[id].Union(&IPSet{})
+}
+
+// AtLeast returns the set of IPs listed by at least k sources. A k less than 1
+// is treated as 1, which gives the union of all sources.
+func (m *IPMultiSet) AtLeast(k int) *IPSet {
+	if k < 1 {
+		k = 1
+	}
+	if m == nil || k > len(m.sources) {
+		return &IPSet{}
+	}
+
+	// levels[i] holds the IPs seen in at least i+1 of the sources visited
+	// so far. Each new source promotes the overlap with one level into the
+	// next level up.
+	levels := make([]*IPSet, k)
+	for i := range levels {
+		levels[i] = &IPSet{}
+	}
+	for _, id := range m.SourceIDs() {
+		s := m.sources[id]
+		for i := k - 1; i > 0; i-- {
+			levels[i] = levels[i].Union(levels[i-1].Intersection(s))
+		}
+		levels[0] = levels[0].Union(s)
+	}
+	return levels[k-1]
+}
@@ -0,0 +1,88 @@
+package netaddr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPMultiSetEmpty(t *testing.T) {
+	m := IPMultiSet{}
+
+	assert.Equal(t, 0, m.Count(Eights))
+	assert.Equal(t, []string{}, m.Sources(Eights))
+	assert.Equal(t, []string{}, m.SourceIDs())
+	assert.Equal(t, big.NewInt(0), m.AtLeast(1).tree.size())
+}
+
+func TestIPMultiSetCount(t *testing.T) {
+	m := IPMultiSet{}
+
+	m.InsertNet("feed-a", Ten24)
+	m.InsertNet("feed-b", Ten24128)
+	m.Insert("feed-c", Ten24Router)
+	m.Insert("feed-c", Ten24Router)
+	m.Insert("feed-c", Eights)
+
+	assert.Equal(t, 2, m.Count(Ten24Router))
+	assert.Equal(t, []string{"feed-a", "feed-c"}, m.Sources(Ten24Router))
+	assert.Equal(t, 2, m.Count(Ten24Broadcast))
+	assert.Equal(t, []string{"feed-a", "feed-b"}, m.Sources(Ten24Broadcast))
+	assert.Equal(t, 1, m.Count(Eights))
+	assert.Equal(t, 0, m.Count(Nines))
+	assert.Equal(t, []string{"feed-a", "feed-b", "feed-c"}, m.SourceIDs())
+}
+
+func TestIPMultiSetRemove(t *testing.T) {
+	m := IPMultiSet{}
+
+	m.InsertNet("feed-a", Ten24)
+	m.Insert("feed-b", Ten24Router)
+	m.Remove("feed-a", Ten24Router)
+	assert.Equal(t, 1, m.Count(Ten24Router))
+	assert.Equal(t, []string{"feed-b"}, m.Sources(Ten24Router))
+
+	m.Remove("feed-b", Ten24Router)
+	assert.Equal(t, 0, m.Count(Ten24Router))
+	assert.Equal(t, []string{"feed-a"}, m.SourceIDs())
+
+	m.RemoveNet("bogus", Ten24)
+	assert.Equal(t, []string{"feed-a"}, m.SourceIDs())
+}
+
+func TestIPMultiSetSource(t *testing.T) {
+	m := IPMultiSet{}
+
+	m.InsertNet("feed-a", Ten24)
+	s := m.Source("feed-a")
+	assert.True(t, s.ContainsNet(Ten24))
+
+	// The returned set is a copy
+	s.Remove(Ten24Router)
+	assert.Equal(t, 1, m.Count(Ten24Router))
+
+	assert.Equal(t, big.NewInt(0), m.Source("bogus").tree.size())
+}
+
+func TestIPMultiSetAtLeast(t *testing.T) {
+	m := IPMultiSet{}
+
+	m.InsertNet("feed-a", parse("10.0.0.0/24"))
+	m.InsertNet("feed-b", parse("10.0.0.128/25"))
+	m.InsertNet("feed-c", parse("10.0.0.192/26"))
+	m.InsertNet("feed-c", parse("192.168.0.0/24"))
+	m.InsertNet("feed-d", parse("192.168.0.0/25"))
+
+	assert.Equal(t, []string{"10.0.0.0/24", "192.168.0.0/24"}, m.AtLeast(0).Strings())
+	assert.Equal(t, []string{"10.0.0.0/24", "192.168.0.0/24"}, m.AtLeast(1).Strings())
+	assert.Equal(t, []string{"10.0.0.128/25", "192.168.0.0/25"}, m.AtLeast(2).Strings())
+	assert.Equal(t, []string{"10.0.0.192/26"}, m.AtLeast(3).Strings())
+	assert.Equal(t, big.NewInt(0), m.AtLeast(4).tree.size())
+
+	assert.Equal(t, big.NewInt(0), m.AtLeast(1<<62).tree.size())
+
+	for _, k := range []int{1, 2, 3} {
+		assert.Equal(t, []error{}, m.AtLeast(k).tree.validate())
+	}
+}
@@ -2,7 +2,12 @@ package netaddr
 
 import (
 	"fmt"
+	"math/big"
 	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 // IPRange range of ips not necessarily aligned to a power of 2
@@ -14,6 +19,130 @@ func (r *IPRange) String() string {
 	return fmt.Sprintf("[%s,%s]", r.First, r.Last)
 }
 
+// NewIPRange returns a new IPRange from first to last inclusive. IPv4
+// addresses, including IPv4 addresses in 16 byte form, are normalized to 4
+// bytes. It returns an error if the range does not pass Validate.
+func NewIPRange(first, last net.IP) (*IPRange, error) {
+	if ip := first.To4(); ip != nil {
+		first = ip
+	}
+	if ip := last.To4(); ip != nil {
+		last = ip
+	}
+	r := &IPRange{First: first, Last: last}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate returns an error if the range is missing an endpoint, if the
+// endpoints are not both 4 byte or both 16 byte addresses, or if First comes
+// after Last. The other IPRange methods assume the range is valid.
+func (r *IPRange) Validate() error {
+	if r.First == nil || r.Last == nil {
+		return fmt.Errorf("IP range %s is missing an address", r)
+	}
+	if len(r.First) != net.IPv4len && len(r.First) != net.IPv6len {
+		return fmt.Errorf("IP range %s has a bad first address", r)
+	}
+	if len(r.Last) != net.IPv4len && len(r.Last) != net.IPv6len {
+		return fmt.Errorf("IP range %s has a bad last address", r)
+	}
+	if len(r.First) != len(r.Last) {
+		return fmt.Errorf("IP range %s mixes 4 byte and 16 byte addresses: %w", r, ErrFamilyMismatch)
+	}
+	if IPLessThan(r.Last, r.First) {
+		return fmt.Errorf("IP range %s has first address after last address", r)
+	}
+	return nil
+}
+
+// ParseIPRange parses an IPRange from a string. It accepts the following forms:
+//
+//	10.0.0.1-10.0.0.50        first and last address separated by a dash
+//	10.0.0.1-50               IPv4 with only the last octet of the last address
+//	2001:db8::1-2001:db8::ff  IPv6 ranges
+//	[10.0.0.1,10.0.0.50]      the form returned by IPRange.String
+//
+// IPv4 addresses are parsed as 4 byte addresses like ParseIP. Errors are
+// returned as *ParseError with the position of the part that failed, wrapping
+// ErrInvalidAddress, ErrFamilyMismatch or ErrInvalidRange.
+func ParseIPRange(str string) (*IPRange, error) {
+	fail := func(pos int, err error) (*IPRange, error) {
+		return nil, &ParseError{Input: str, Pos: pos, Err: err}
+	}
+	leading := func(s string) int {
+		return len(s) - len(strings.TrimLeftFunc(s, unicode.IsSpace))
+	}
+
+	start := leading(str)
+	text := strings.TrimSpace(str)
+	sep := "-"
+	if strings.HasPrefix(text, "[") {
+		if !strings.HasSuffix(text, "]") {
+			return fail(start+len(text), fmt.Errorf("missing closing bracket: %w", ErrInvalidRange))
+		}
+		text = text[1 : len(text)-1]
+		start++
+		sep = ","
+	}
+
+	parts := strings.Split(text, sep)
+	if len(parts) != 2 {
+		pos := start + len(text)
+		if len(parts) > 2 {
+			pos = start + len(parts[0]) + len(sep) + len(parts[1])
+		}
+		return fail(pos, fmt.Errorf("expected two addresses separated by %q: %w", sep, ErrInvalidRange))
+	}
+	firstStart := start + leading(parts[0])
+	lastStart := start + len(parts[0]) + len(sep) + leading(parts[1])
+	firstStr, lastStr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	first, err := ParseIPErr(firstStr)
+	if err != nil {
+		return nil, rebaseError(err, str, firstStart)
+	}
+
+	var last net.IP
+	if sep == "-" && len(first) == net.IPv4len && !strings.ContainsAny(lastStr, ".:") {
+		// The last octet shorthand, e.g. 10.0.0.1-50
+		octet, err := strconv.ParseUint(lastStr, 10, 8)
+		if err != nil {
+			return fail(lastStart, fmt.Errorf("bad last octet: %w", ErrInvalidAddress))
+		}
+		last = IPv4(first[0], first[1], first[2], byte(octet))
+	} else if last, err = ParseIPErr(lastStr); err != nil {
+		return nil, rebaseError(err, str, lastStart)
+	}
+
+	if len(first) != len(last) {
+		return fail(lastStart, ErrFamilyMismatch)
+	}
+	if IPLessThan(last, first) {
+		return fail(lastStart, fmt.Errorf("first address comes after last address: %w", ErrInvalidRange))
+	}
+	return &IPRange{First: first, Last: last}, nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It formats the range as
+// "first-last" which ParseIPRange accepts.
+func (r *IPRange) MarshalText() ([]byte, error) {
+	return []byte(r.First.String() + "-" + r.Last.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts any of the
+// forms accepted by ParseIPRange.
+func (r *IPRange) UnmarshalText(text []byte) error {
+	parsed, err := ParseIPRange(string(text))
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
+
 // IPRangeFromIPNet get an IPRange from an *ip.Net
 func IPRangeFromIPNet(cidr *net.IPNet) *IPRange {
 	return &IPRange{
@@ -22,6 +151,12 @@ func IPRangeFromIPNet(cidr *net.IPNet) *IPRange {
 	}
 }
 
+// CIDRs returns the smallest list of CIDRs that covers exactly the IPs in the
+// range, sorted by IP. It returns nil if the range is not valid.
+func (r *IPRange) CIDRs() []*net.IPNet {
+	return rangeToNets(r.First, r.Last)
+}
+
 // Minus returns the ranges in r that are not in b
 func (r *IPRange) Minus(b *IPRange) []*IPRange {
 	diff := []*IPRange{}
@@ -35,6 +170,62 @@ func (r *IPRange) Minus(b *IPRange) []*IPRange {
 	return diff
 }
 
+// MinusRanges returns the ranges in r that are not in any of the ranges in bs.
+// The result is sorted and does not modify r or bs.
+func (r *IPRange) MinusRanges(bs []*IPRange) []*IPRange {
+	diff := []*IPRange{}
+	rest := &IPRange{First: r.First, Last: r.Last}
+	for _, b := range MergeRanges(bs) {
+		if !rest.Overlaps(b) {
+			if IPLessThan(rest.Last, b.First) {
+				break
+			}
+			continue
+		}
+		// b is sorted so only the part of rest after b is left to check
+		pieces := rest.Minus(b)
+		rest = nil
+		for _, piece := range pieces {
+			if IPLessThan(piece.First, b.First) {
+				diff = append(diff, piece)
+			} else {
+				rest = piece
+			}
+		}
+		if rest == nil {
+			return diff
+		}
+	}
+	return append(diff, rest)
+}
+
+// MergeRanges returns the IPs in the given ranges as a list of ranges that
+// are sorted by IPLessThan and which neither overlap nor are adjacent. It
+// does not modify the given ranges.
+func MergeRanges(ranges []*IPRange) []*IPRange {
+	sorted := make([]*IPRange, len(ranges))
+	copy(sorted, ranges)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c := compareIPBytes(sorted[i].First, sorted[j].First); c != 0 {
+			return c < 0
+		}
+		return IPLessThan(sorted[i].Last, sorted[j].Last)
+	})
+
+	merged := []*IPRange{}
+	for _, r := range sorted {
+		if len(merged) != 0 {
+			last := merged[len(merged)-1]
+			if union := last.Union(r); union != nil {
+				merged[len(merged)-1] = union
+				continue
+			}
+		}
+		merged = append(merged, &IPRange{First: r.First, Last: r.Last})
+	}
+	return merged
+}
+
 // Contains returns true if b is contained in r
 func (r *IPRange) Contains(b *IPRange) bool {
 	if (IPLessThan(r.First, b.First) || r.First.Equal(b.First)) &&
@@ -43,3 +234,99 @@ func (r *IPRange) Contains(b *IPRange) bool {
 	}
 	return false
 }
+
+// ContainsIP returns true if ip is in r. IPv4 addresses are matched in either
+// 4 or 16 byte form, like NewIPRange accepts them. An empty IP or range
+// contains nothing.
+func (r *IPRange) ContainsIP(ip net.IP) bool {
+	ip = r.sameForm(ip)
+	if len(ip) == 0 || len(ip) != len(r.First) {
+		return false
+	}
+	return !IPLessThan(ip, r.First) && !IPLessThan(r.Last, ip)
+}
+
+// sameForm returns ip in the same 4 or 16 byte form as the range, or nil if
+// it is IPv6 and the range is IPv4
+func (r *IPRange) sameForm(ip net.IP) net.IP {
+	if len(r.First) == net.IPv4len {
+		return ip.To4()
+	}
+	return ip.To16()
+}
+
+// Overlaps returns true if r and b have at least one IP in common
+func (r *IPRange) Overlaps(b *IPRange) bool {
+	if len(r.First) == 0 || len(r.First) != len(b.First) {
+		return false
+	}
+	return !IPLessThan(r.Last, b.First) && !IPLessThan(b.Last, r.First)
+}
+
+// IsAdjacent returns true if b starts right after r ends or r starts right
+// after b ends
+func (r *IPRange) IsAdjacent(b *IPRange) bool {
+	if len(r.First) != len(b.First) {
+		return false
+	}
+	if IPLessThan(r.Last, b.First) && incrementIP(r.Last).Equal(b.First) {
+		return true
+	}
+	return IPLessThan(b.Last, r.First) && incrementIP(b.Last).Equal(r.First)
+}
+
+// Intersect returns the range of IPs in both r and b or nil if they don't
+// overlap
+func (r *IPRange) Intersect(b *IPRange) *IPRange {
+	if !r.Overlaps(b) {
+		return nil
+	}
+	return &IPRange{First: IPMax(r.First, b.First), Last: IPMin(r.Last, b.Last)}
+}
+
+// Union returns the range of IPs in either r or b. It returns nil if r and b
+// neither overlap nor are adjacent because the result would not be a range.
+func (r *IPRange) Union(b *IPRange) *IPRange {
+	if !r.Overlaps(b) && !r.IsAdjacent(b) {
+		return nil
+	}
+	return &IPRange{First: IPMin(r.First, b.First), Last: IPMax(r.Last, b.Last)}
+}
+
+// Each calls visit with each IP in the range in order until visit returns
+// false. It uses constant memory no matter how large the range is. A range
+// which fails Validate has no IPs to visit.
+func (r *IPRange) Each(visit func(net.IP) bool) {
+	if r.Validate() != nil {
+		return
+	}
+	for ip := r.First; visit(ip); ip = incrementIP(ip) {
+		if !IPLessThan(ip, r.Last) {
+			return
+		}
+	}
+}
+
+// At returns the IP at the given offset from the start of the range, or nil
+// if the offset is outside of the range. The offset of First is 0.
+func (r *IPRange) At(offset *big.Int) net.IP {
+	if offset.Sign() < 0 || offset.Cmp(r.Size()) >= 0 {
+		return nil
+	}
+	return bigIntToIP(big.NewInt(0).Add(IPToInt(r.First), offset), len(r.First))
+}
+
+// Offset returns the offset of the given IP from the start of the range, or
+// nil if the IP is not in the range. It is the inverse of At.
+func (r *IPRange) Offset(ip net.IP) *big.Int {
+	if !r.ContainsIP(ip) {
+		return nil
+	}
+	return big.NewInt(0).Sub(IPToInt(r.sameForm(ip)), IPToInt(r.First))
+}
+
+// Size returns the number of IPs in the range
+func (r *IPRange) Size() *big.Int {
+	s := big.NewInt(0).Sub(IPToInt(r.Last), IPToInt(r.First))
+	return s.Add(s, big.NewInt(1))
+}
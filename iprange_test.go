@@ -1,7 +1,10 @@
 package netaddr
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,3 +91,341 @@ func TestIPRangeContains(t *testing.T) {
 		}
 	}
 }
+
+func TestParseIPRange(t *testing.T) {
+	for _, tc := range []struct {
+		in, first, last string
+	}{
+		{"10.0.0.1-10.0.0.50", "10.0.0.1", "10.0.0.50"},
+		{" 10.0.0.1 - 10.0.0.50 ", "10.0.0.1", "10.0.0.50"},
+		{"10.0.0.1-50", "10.0.0.1", "10.0.0.50"},
+		{"10.0.0.1-1", "10.0.0.1", "10.0.0.1"},
+		{"10.0.0.0-10.0.1.255", "10.0.0.0", "10.0.1.255"},
+		{"2001:db8::1-2001:db8::ff", "2001:db8::1", "2001:db8::ff"},
+		{"[10.0.0.1,10.0.0.50]", "10.0.0.1", "10.0.0.50"},
+		{"[2001:db8::1, 2001:db8::ff]", "2001:db8::1", "2001:db8::ff"},
+	} {
+		r, err := ParseIPRange(tc.in)
+		if assert.Nil(t, err, tc.in) {
+			assert.Equal(t, ParseIP(tc.first), r.First)
+			assert.Equal(t, ParseIP(tc.last), r.Last)
+		}
+	}
+}
+
+func TestParseIPRangeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+		err error
+	}{
+		{"", 0, ErrInvalidRange},
+		{"10.0.0.1", 8, ErrInvalidRange},
+		{"10.0.0.1-10.0.0.5-10.0.0.9", 17, ErrInvalidRange},
+		{"10.0.0.300-10.0.0.5", 7, ErrInvalidAddress},
+		{"10.0.0.1-bogus", 9, ErrInvalidAddress},
+		{"10.0.0.1-256", 9, ErrInvalidAddress},
+		{"10.0.0.1-10.0.0", 15, ErrInvalidAddress},
+		{" 10.0.0.1 - 10.0.0.x", 19, ErrInvalidAddress},
+		{"2001:db8::1-ff", 12, ErrInvalidAddress},
+		{"10.0.0.1-2001:db8::", 9, ErrFamilyMismatch},
+		{"10.0.0.50-10.0.0.1", 10, ErrInvalidRange},
+		{"10.0.0.50-1", 10, ErrInvalidRange},
+		{"[10.0.0.1,10.0.0.50", 19, ErrInvalidRange},
+		{"[10.0.0.1-10.0.0.50]", 19, ErrInvalidRange},
+	} {
+		r, err := ParseIPRange(tc.in)
+		assert.Nil(t, r)
+		if assert.IsType(t, &ParseError{}, err, tc.in) {
+			assert.Equal(t, tc.in, err.(*ParseError).Input)
+			assert.Equal(t, tc.pos, err.(*ParseError).Pos, tc.in)
+			assert.True(t, errors.Is(err, tc.err), tc.in)
+		}
+	}
+	_, err := ParseIPRange("10.0.0.50-1")
+	assert.EqualError(t, err, `first address comes after last address: invalid IP range: "10.0.0.50-1" at position 10`)
+	_, err = ParseIPRange("10.0.0.1-999")
+	assert.EqualError(t, err, `bad last octet: invalid IP address: "10.0.0.1-999" at position 9`)
+	assert.True(t, errors.Is((&IPRange{ParseIP("10.0.0.1"), ParseIP("2001:db8::")}).Validate(), ErrFamilyMismatch))
+}
+
+func TestIPRangeText(t *testing.T) {
+	for _, in := range []string{"10.0.0.1-10.0.0.50", "2001:db8::1-2001:db8::ff"} {
+		r, err := ParseIPRange(in)
+		assert.Nil(t, err)
+
+		text, err := r.MarshalText()
+		assert.Nil(t, err)
+		assert.Equal(t, in, string(text))
+
+		parsed := &IPRange{}
+		assert.Nil(t, parsed.UnmarshalText(text))
+		assert.Equal(t, r, parsed)
+
+		parsed = &IPRange{}
+		assert.Nil(t, parsed.UnmarshalText([]byte(r.String())))
+		assert.Equal(t, r, parsed)
+	}
+
+	parsed := &IPRange{}
+	assert.NotNil(t, parsed.UnmarshalText([]byte("bogus")))
+}
+
+func TestIPRangeCIDRs(t *testing.T) {
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"10.0.0.0-10.0.0.255", "[10.0.0.0/24]"},
+		{"10.0.0.1-10.0.0.1", "[10.0.0.1/32]"},
+		{"10.0.0.1-10.0.1.0", "[10.0.0.1/32 10.0.0.2/31 10.0.0.4/30 10.0.0.8/29 10.0.0.16/28 10.0.0.32/27 10.0.0.64/26 10.0.0.128/25 10.0.1.0/32]"},
+		{"0.0.0.0-255.255.255.255", "[0.0.0.0/0]"},
+		{"0.0.0.0-127.255.255.255", "[0.0.0.0/1]"},
+		{"::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "[::/0]"},
+		{"2001:db8::ffff-2001:db8::2:0", "[2001:db8::ffff/128 2001:db8::1:0/112 2001:db8::2:0/128]"},
+	} {
+		r, err := ParseIPRange(tc.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.result, fmt.Sprintf("%s", r.CIDRs()))
+	}
+
+	assert.Nil(t, (&IPRange{ParseIP("10.0.0.2"), ParseIP("10.0.0.1")}).CIDRs())
+	assert.Nil(t, (&IPRange{}).CIDRs())
+}
+
+func TestNewIPRange(t *testing.T) {
+	r, err := NewIPRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.50"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(r.First))
+	assert.Equal(t, 4, len(r.Last))
+	assert.Equal(t, "[10.0.0.1,10.0.0.50]", r.String())
+
+	r, err = NewIPRange(ParseIP("10.0.0.1"), net.ParseIP("10.0.0.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, &IPRange{ParseIP("10.0.0.1"), ParseIP("10.0.0.1")}, r)
+
+	r, err = NewIPRange(ParseIP("2001:db8::1"), ParseIP("2001:db8::ff"))
+	assert.Nil(t, err)
+	assert.Equal(t, &IPRange{ParseIP("2001:db8::1"), ParseIP("2001:db8::ff")}, r)
+
+	r, err = NewIPRange(ParseIP("10.0.0.50"), ParseIP("10.0.0.1"))
+	assert.NotNil(t, err)
+	assert.Nil(t, r)
+
+	r, err = NewIPRange(ParseIP("10.0.0.1"), ParseIP("2001:db8::ff"))
+	assert.NotNil(t, err)
+	assert.Nil(t, r)
+
+	r, err = NewIPRange(nil, ParseIP("10.0.0.1"))
+	assert.NotNil(t, err)
+	assert.Nil(t, r)
+}
+
+func TestIPRangeValidate(t *testing.T) {
+	for _, tc := range []struct {
+		r   *IPRange
+		err string
+	}{
+		{&IPRange{ParseIP("10.0.0.1"), ParseIP("10.0.0.50")}, ""},
+		{&IPRange{ParseIP("10.0.0.1"), ParseIP("10.0.0.1")}, ""},
+		{&IPRange{ParseIP("2001:db8::1"), ParseIP("2001:db8::ff")}, ""},
+		{&IPRange{}, "IP range [<nil>,<nil>] is missing an address"},
+		{&IPRange{ParseIP("10.0.0.1"), nil}, "IP range [10.0.0.1,<nil>] is missing an address"},
+		{&IPRange{net.IP{10, 0, 0}, ParseIP("10.0.0.1")}, "IP range [?0a0000,10.0.0.1] has a bad first address"},
+		{&IPRange{ParseIP("10.0.0.1"), net.IP{10, 0, 0}}, "IP range [10.0.0.1,?0a0000] has a bad last address"},
+		{&IPRange{ParseIP("10.0.0.1"), net.ParseIP("10.0.0.50")}, "IP range [10.0.0.1,10.0.0.50] mixes 4 byte and 16 byte addresses: address family mismatch"},
+		{&IPRange{ParseIP("10.0.0.50"), ParseIP("10.0.0.1")}, "IP range [10.0.0.50,10.0.0.1] has first address after last address"},
+	} {
+		err := tc.r.Validate()
+		if tc.err == "" {
+			assert.Nil(t, err)
+		} else if assert.NotNil(t, err) {
+			assert.Equal(t, tc.err, err.Error())
+		}
+	}
+}
+
+// Just a little shortcut for parsing an IPRange.
+func rng(str string) *IPRange {
+	r, _ := ParseIPRange(str)
+	return r
+}
+
+func TestIPRangeContainsIP(t *testing.T) {
+	r := rng("10.0.0.10-10.0.0.20")
+	assert.False(t, r.ContainsIP(ParseIP("10.0.0.9")))
+	assert.True(t, r.ContainsIP(ParseIP("10.0.0.10")))
+	assert.True(t, r.ContainsIP(ParseIP("10.0.0.15")))
+	assert.True(t, r.ContainsIP(ParseIP("10.0.0.20")))
+	assert.False(t, r.ContainsIP(ParseIP("10.0.0.21")))
+	assert.True(t, r.ContainsIP(net.ParseIP("10.0.0.15")))
+	assert.False(t, r.ContainsIP(net.ParseIP("10.0.0.21")))
+	assert.False(t, r.ContainsIP(ParseIP("::a00:f")))
+	assert.False(t, r.ContainsIP(nil))
+	assert.False(t, (&IPRange{}).ContainsIP(nil))
+	assert.False(t, (&IPRange{}).ContainsIP(net.IP{}))
+	assert.False(t, (&IPRange{}).ContainsIP(ParseIP("10.0.0.1")))
+}
+
+func TestIPRangeOverlapsIntersectUnion(t *testing.T) {
+	for _, tc := range []struct {
+		a, b      string
+		overlaps  bool
+		adjacent  bool
+		intersect string
+		union     string
+	}{
+		{"10.0.0.10-10.0.0.20", "10.0.0.0-10.0.0.8", false, false, "<nil>", "<nil>"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.0-10.0.0.9", false, true, "<nil>", "[10.0.0.0,10.0.0.20]"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.0-10.0.0.10", true, false, "[10.0.0.10,10.0.0.10]", "[10.0.0.0,10.0.0.20]"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.12-10.0.0.15", true, false, "[10.0.0.12,10.0.0.15]", "[10.0.0.10,10.0.0.20]"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.15-10.0.0.30", true, false, "[10.0.0.15,10.0.0.20]", "[10.0.0.10,10.0.0.30]"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.21-10.0.0.30", false, true, "<nil>", "[10.0.0.10,10.0.0.30]"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.22-10.0.0.30", false, false, "<nil>", "<nil>"},
+		{"0.0.0.0-0.0.0.1", "255.255.255.0-255.255.255.255", false, false, "<nil>", "<nil>"},
+		{"10.0.0.10-10.0.0.20", "::a00:a-::a00:14", false, false, "<nil>", "<nil>"},
+		{"2001:db8::-2001:db8::ffff", "2001:db8::1:0-2001:db8::1:ffff", false, true, "<nil>", "[2001:db8::,2001:db8::1:ffff]"},
+	} {
+		a, b := rng(tc.a), rng(tc.b)
+		assert.Equal(t, tc.overlaps, a.Overlaps(b), "%s %s", tc.a, tc.b)
+		assert.Equal(t, tc.overlaps, b.Overlaps(a), "%s %s", tc.a, tc.b)
+		assert.Equal(t, tc.adjacent, a.IsAdjacent(b), "%s %s", tc.a, tc.b)
+		assert.Equal(t, tc.adjacent, b.IsAdjacent(a), "%s %s", tc.a, tc.b)
+		assert.Equal(t, tc.intersect, fmt.Sprintf("%s", a.Intersect(b)))
+		assert.Equal(t, tc.intersect, fmt.Sprintf("%s", b.Intersect(a)))
+		assert.Equal(t, tc.union, fmt.Sprintf("%s", a.Union(b)))
+		assert.Equal(t, tc.union, fmt.Sprintf("%s", b.Union(a)))
+	}
+}
+
+func TestIPRangeOverlapsEmpty(t *testing.T) {
+	empty := &IPRange{}
+	assert.False(t, empty.Overlaps(&IPRange{}))
+	assert.False(t, empty.Overlaps(rng("10.0.0.1-10.0.0.2")))
+	assert.Nil(t, empty.Intersect(&IPRange{}))
+}
+
+func TestIPRangeSize(t *testing.T) {
+	assert.Equal(t, "1", rng("10.0.0.1-10.0.0.1").Size().String())
+	assert.Equal(t, "50", rng("10.0.0.1-10.0.0.50").Size().String())
+	assert.Equal(t, "4294967296", rng("0.0.0.0-255.255.255.255").Size().String())
+	assert.Equal(t, NetSize(parse("::/0")), rng("::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff").Size())
+}
+
+func TestMergeRanges(t *testing.T) {
+	assert.Equal(t, []*IPRange{}, MergeRanges(nil))
+
+	ranges := []*IPRange{
+		rng("10.0.0.50-10.0.0.60"),
+		rng("2001:db8::1-2001:db8::ff"),
+		rng("10.0.0.1-10.0.0.10"),
+		rng("10.0.0.11-10.0.0.20"),
+		rng("10.0.0.15-10.0.0.18"),
+		rng("10.0.0.55-10.0.0.70"),
+		rng("2001:db8::100-2001:db8::1ff"),
+		rng("10.0.0.72-10.0.0.72"),
+	}
+	assert.Equal(t, "[[10.0.0.1,10.0.0.20] [10.0.0.50,10.0.0.70] [10.0.0.72,10.0.0.72] [2001:db8::1,2001:db8::1ff]]", fmt.Sprintf("%s", MergeRanges(ranges)))
+
+	// The input is left alone
+	assert.Equal(t, "[10.0.0.50,10.0.0.60]", ranges[0].String())
+	assert.Equal(t, "[10.0.0.1,10.0.0.10]", ranges[2].String())
+}
+
+func TestIPRangeMinusRanges(t *testing.T) {
+	r := rng("10.0.0.0-10.0.0.255")
+	for _, tc := range []struct {
+		bs     []*IPRange
+		result string
+	}{
+		{nil, "[[10.0.0.0,10.0.0.255]]"},
+		{[]*IPRange{rng("9.0.0.0-9.0.0.255"), rng("11.0.0.0-11.0.0.255")}, "[[10.0.0.0,10.0.0.255]]"},
+		{[]*IPRange{rng("9.0.0.0-10.0.0.9"), rng("10.0.0.250-11.0.0.0")}, "[[10.0.0.10,10.0.0.249]]"},
+		{[]*IPRange{rng("10.0.0.100-10.0.0.120"), rng("10.0.0.10-10.0.0.20"), rng("10.0.0.15-10.0.0.30")}, "[[10.0.0.0,10.0.0.9] [10.0.0.31,10.0.0.99] [10.0.0.121,10.0.0.255]]"},
+		{[]*IPRange{rng("10.0.0.10-10.0.0.20"), rng("10.0.0.200-10.0.0.255")}, "[[10.0.0.0,10.0.0.9] [10.0.0.21,10.0.0.199]]"},
+		{[]*IPRange{rng("10.0.0.0-10.0.0.127"), rng("10.0.0.128-10.0.0.255")}, "[]"},
+		{[]*IPRange{rng("::a00:0-::a00:ff")}, "[[10.0.0.0,10.0.0.255]]"},
+	} {
+		assert.Equal(t, tc.result, fmt.Sprintf("%s", r.MinusRanges(tc.bs)))
+	}
+	assert.Equal(t, "[10.0.0.0,10.0.0.255]", r.String())
+}
+
+func TestIPRangeEach(t *testing.T) {
+	ips := []net.IP{}
+	rng("10.0.0.254-10.0.1.1").Each(func(ip net.IP) bool {
+		ips = append(ips, ip)
+		return true
+	})
+	assert.Equal(t, "[10.0.0.254 10.0.0.255 10.0.1.0 10.0.1.1]", fmt.Sprintf("%s", ips))
+
+	ips = []net.IP{}
+	rng("255.255.255.254-255.255.255.255").Each(func(ip net.IP) bool {
+		ips = append(ips, ip)
+		return true
+	})
+	assert.Equal(t, "[255.255.255.254 255.255.255.255]", fmt.Sprintf("%s", ips))
+
+	// Stops early on a huge range
+	ips = []net.IP{}
+	rng("::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff").Each(func(ip net.IP) bool {
+		ips = append(ips, ip)
+		return len(ips) < 3
+	})
+	assert.Equal(t, "[:: ::1 ::2]", fmt.Sprintf("%s", ips))
+
+	// 16 byte ranges run into the IPv4-mapped addresses like any others
+	ips = []net.IP{}
+	rng("::fffe:ffff:ffff-::ffff:0.0.0.1").Each(func(ip net.IP) bool {
+		ips = append(ips, ip)
+		return true
+	})
+	assert.Equal(t, 3, len(ips))
+
+	// Invalid ranges have nothing to visit instead of wrapping around
+	for _, r := range []*IPRange{
+		{First: ParseIP("10.0.0.2"), Last: ParseIP("10.0.0.1")},
+		{First: ParseIP("10.0.0.1"), Last: ParseIP("::a00:2")},
+		{First: ParseIP("2001:db8::2"), Last: ParseIP("2001:db8::1")},
+		{},
+	} {
+		calls := 0
+		r.Each(func(ip net.IP) bool {
+			calls++
+			return calls < 10
+		})
+		assert.Equal(t, 0, calls, "%s", r)
+	}
+}
+
+func TestIPRangeAtOffset(t *testing.T) {
+	r := rng("10.0.0.200-10.0.1.10")
+
+	assert.Equal(t, ParseIP("10.0.0.200"), r.At(big.NewInt(0)))
+	assert.Equal(t, ParseIP("10.0.1.0"), r.At(big.NewInt(56)))
+	assert.Equal(t, ParseIP("10.0.1.10"), r.At(big.NewInt(66)))
+	assert.Nil(t, r.At(big.NewInt(67)))
+	assert.Nil(t, r.At(big.NewInt(-1)))
+
+	assert.Equal(t, "0", r.Offset(ParseIP("10.0.0.200")).String())
+	assert.Equal(t, "56", r.Offset(ParseIP("10.0.1.0")).String())
+	assert.Equal(t, "66", r.Offset(ParseIP("10.0.1.10")).String())
+	assert.Equal(t, "66", r.Offset(net.ParseIP("10.0.1.10")).String())
+	assert.Nil(t, r.Offset(ParseIP("10.0.1.11")))
+	assert.Nil(t, r.Offset(ParseIP("2001:db8::")))
+
+	r = rng("2001:db8::-2001:db8::ffff:ffff:ffff:ffff")
+	offset := big.NewInt(0).Lsh(big.NewInt(1), 48)
+	assert.Equal(t, ParseIP("2001:db8::1:0:0:0"), r.At(offset))
+	assert.Equal(t, offset, r.Offset(ParseIP("2001:db8::1:0:0:0")))
+}
+
+func TestMergeRangesMixedForms(t *testing.T) {
+	// 16 byte IPv4 ranges don't come between the 4 byte ranges they overlap
+	merged := MergeRanges([]*IPRange{
+		rng("10.0.0.0-10.0.0.5"),
+		rng("::ffff:10.0.0.3-::ffff:10.0.0.4"),
+		rng("10.0.0.6-10.0.0.9"),
+	})
+	assert.Equal(t, "[[10.0.0.0,10.0.0.9] [10.0.0.3,10.0.0.4]]", fmt.Sprintf("%s", merged))
+	assert.Equal(t, net.IPv6len, len(merged[1].First))
+}
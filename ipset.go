@@ -1,7 +1,12 @@
 package netaddr
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
 	"net"
+	"strconv"
+	"strings"
 )
 
 // IPSet is a set of IP addresses
@@ -123,6 +128,115 @@ func (s *IPSet) GetNetworks() []*net.IPNet {
 	return networks
 }
 
+// Split divides the set into k disjoint sets which together make up this set.
+// The IPs are dealt out in order, IPv4 before IPv6, so that the sizes of any
+// two parts differ by at most one address and each part is made of as few
+// CIDRs as a contiguous slice of the set allows. If the set has fewer than k
+// IPs then some of the parts are empty. It returns nil if k is less than 1.
+func (s *IPSet) Split(k int) []*IPSet {
+	if k < 1 {
+		return nil
+	}
+
+	ranges := s.ranges()
+	total := big.NewInt(0)
+	for _, r := range ranges {
+		total.Add(total, r.Size())
+	}
+
+	// Walk the ranges once. next is the first IP of the current range which
+	// hasn't been dealt out yet and left is how many of its IPs remain.
+	parts := make([]*IPSet, k)
+	j, next, left := 0, big.NewInt(0), big.NewInt(0)
+	if len(ranges) > 0 {
+		next, left = IPToInt(ranges[0].First), ranges[0].Size()
+	}
+	dealt := big.NewInt(0)
+	for i := range parts {
+		parts[i] = &IPSet{}
+
+		// This part gets the IPs at positions [dealt, end) in the set
+		end := big.NewInt(0).Mul(total, big.NewInt(int64(i+1)))
+		end.Div(end, big.NewInt(int64(k)))
+		need := big.NewInt(0).Sub(end, dealt)
+		dealt = end
+
+		for need.Sign() > 0 {
+			take := big.NewInt(0).Set(left)
+			if need.Cmp(left) < 0 {
+				take.Set(need)
+			}
+			last := big.NewInt(0).Add(next, take)
+			last.Sub(last, big.NewInt(1))
+			size := len(ranges[j].First)
+			for _, n := range rangeToNets(bigIntToIP(next, size), bigIntToIP(last, size)) {
+				parts[i].InsertNet(n)
+			}
+
+			need.Sub(need, take)
+			left.Sub(left, take)
+			next = last.Add(last, big.NewInt(1))
+			if left.Sign() == 0 && j+1 < len(ranges) {
+				j++
+				next, left = IPToInt(ranges[j].First), ranges[j].Size()
+			}
+		}
+	}
+	return parts
+}
+
+// LongPrefixMode tells GetNetworksWithin what to do with networks whose prefix
+// is longer than the maximum allowed.
+type LongPrefixMode int
+
+const (
+	// RejectLongPrefixes causes GetNetworksWithin to return an error
+	RejectLongPrefixes LongPrefixMode = iota
+	// WidenLongPrefixes causes GetNetworksWithin to replace the network
+	// with the enclosing network of the maximum prefix length. The result
+	// may include IPs which are not in the set.
+	WidenLongPrefixes
+)
+
+// GetNetworksWithin retrieves the networks of the given address family
+// (net.IPv4len or net.IPv6len) in the set with each prefix length between
+// minLen and maxLen inclusive. Networks shorter than minLen are split into
+// networks of length minLen. Networks longer than maxLen are handled according
+// to mode. Beware that splitting a large network may return a very large list.
+// Errors wrap ErrFamilyMismatch for a bad family and ErrInvalidPrefixLength for
+// bad bounds or, with RejectLongPrefixes, a network longer than maxLen.
+func (s *IPSet) GetNetworksWithin(family, minLen, maxLen int, mode LongPrefixMode) ([]*net.IPNet, error) {
+	if err := checkFamily(family); err != nil {
+		return nil, err
+	}
+	bits := 8 * family
+	if minLen < 0 || maxLen > bits || minLen > maxLen {
+		return nil, fmt.Errorf("bad prefix length bounds /%d to /%d: %w", minLen, maxLen, ErrInvalidPrefixLength)
+	}
+
+	bounded := &IPSet{}
+	for _, n := range s.GetNetworks() {
+		if len(n.IP) != family {
+			continue
+		}
+		ones, _ := n.Mask.Size()
+		if ones > maxLen {
+			if mode != WidenLongPrefixes {
+				return nil, fmt.Errorf("network %s is longer than /%d: %w", n, maxLen, ErrInvalidPrefixLength)
+			}
+			n = &net.IPNet{IP: n.IP.Mask(net.CIDRMask(maxLen, bits)), Mask: net.CIDRMask(maxLen, bits)}
+		}
+		bounded.InsertNet(n)
+	}
+
+	// Inserting may have combined networks so only split them at the end
+	networks := []*net.IPNet{}
+	for _, n := range bounded.GetNetworks() {
+		networks = append(networks, splitNet(n, minLen)...)
+	}
+	return networks, nil
+}
+
 // Intersection computes the set intersect between this IPSet and another one
 // It returns a new set which is the intersection.
 func (s *IPSet) Intersection(set1 *IPSet) (interSect *IPSet) {
@@ -140,10 +254,95 @@ func (s *IPSet) Intersection(set1 *IPSet) (interSect *IPSet) {
 	return
 }
 
-// String returns a list of IP Networks
-func (s *IPSet) String() (str []string) {
+// Strings returns a list of IP Networks
+func (s *IPSet) Strings() (str []string) {
+	if s == nil {
+		return
+	}
 	for node := s.tree.first(); node != nil; node = node.next() {
 		str = append(str, node.net.String())
 	}
 	return
 }
+
+// String returns the IP networks in the set as a single string, e.g.
+// "[10.0.0.0/24 192.168.0.248/29]"
+func (s *IPSet) String() string {
+	return "[" + strings.Join(s.Strings(), " ") + "]"
+}
+
+// rangeString returns the IPs in the set as a list of ranges, e.g.
+// "[[10.0.0.0,10.0.0.255] [192.168.0.248,192.168.0.255]]"
+func (s *IPSet) rangeString() string {
+	strs := []string{}
+	for _, r := range s.ranges() {
+		strs = append(strs, r.String())
+	}
+	return "[" + strings.Join(strs, " ") + "]"
+}
+
+// Format implements fmt.Formatter. The verbs %s and %v print the set as a list
+// of CIDRs like String does. The verb %r prints it as a list of ranges
+// instead. The verb %q prints the quoted CIDR list. Width and flags apply to
+// the whole list, e.g. %-40s pads it on the right. A nil set prints as an
+// empty list. Like the rest of IPSet's methods, Format has a pointer receiver
+// so pass &set to format an IPSet value.
+func (s *IPSet) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's', 'v':
+		fmt.Fprintf(f, formatDirective(f, 's'), s.String())
+	case 'q':
+		fmt.Fprintf(f, formatDirective(f, 'q'), s.String())
+	case 'r':
+		fmt.Fprintf(f, formatDirective(f, 's'), s.rangeString())
+	default:
+		fmt.Fprintf(f, "%%!%c(*netaddr.IPSet=%s)", verb, s.String())
+	}
+}
+
+// formatDirective rebuilds the directive, e.g. "%-10s", which was used to
+// format a value with the given verb substituted
+func formatDirective(f fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if precision, ok := f.Precision(); ok {
+		directive += "." + strconv.Itoa(precision)
+	}
+	return directive + string(verb)
+}
+
+// ranges returns the IPs in the set as a list of non-adjacent ranges sorted by
+// IPLessThan. The ranges depend only on the IPs in the set and not on how the
+// tree happens to be shaped.
+func (s *IPSet) ranges() []*IPRange {
+	ranges := []*IPRange{}
+	if s != nil {
+		s.tree.walk(func(node *ipTree) {
+			ranges = append(ranges, IPRangeFromIPNet(node.net))
+		})
+	}
+	return MergeRanges(ranges)
+}
+
+// Hash returns a SHA-256 digest of the set's canonical CIDR list. Two sets
+// containing the same IPs have the same hash no matter the order in which the
+// IPs were inserted or removed.
+func (s *IPSet) Hash() []byte {
+	h := sha256.New()
+	for _, r := range s.ranges() {
+		for _, n := range rangeToNets(r.First, r.Last) {
+			ones, _ := n.Mask.Size()
+			h.Write([]byte{byte(len(n.IP))})
+			h.Write(n.IP)
+			h.Write([]byte{byte(ones)})
+		}
+	}
+	return h.Sum(nil)
+}
@@ -1,6 +1,7 @@
 package netaddr
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -351,8 +352,8 @@ func testIntersection(t *testing.T, input1 []string, input2 []string, output []s
 		interSect.InsertNet(cidr)
 	}
 	set := set1.Intersection(set2)
-	s1 := set.String()
-	intSect := interSect.String()
+	s1 := set.Strings()
+	intSect := interSect.Strings()
 	if !assert.Equal(t, intSect, s1) {
 		t.Logf("\nEXPECTED: %s\nACTUAL: %s\n", intSect, s1)
 	}
@@ -431,3 +432,193 @@ func TestGetNetworks(t *testing.T) {
 	s.Remove(ParseIP("10.0.0.129"))
 	assert.Equal(t, "[10.0.0.128/32 10.0.0.130/31 10.0.0.132/30 10.0.0.136/29 10.0.0.144/28 10.0.0.160/27 10.0.0.192/26]", fmt.Sprintf("%s", s.GetNetworks()))
 }
+
+func TestIPSetHash(t *testing.T) {
+	empty := &IPSet{}
+	var nilSet *IPSet
+	assert.Equal(t, 32, len(empty.Hash()))
+	assert.Equal(t, empty.Hash(), nilSet.Hash())
+
+	// Build the same set in two different ways
+	set1 := &IPSet{}
+	set1.InsertNet(Ten24)
+	set1.InsertNet(V6Net1)
+	set1.Insert(Eights)
+
+	set2 := &IPSet{}
+	set2.Insert(Eights)
+	set2.InsertNet(V6Net1)
+	set2.InsertNet(parse("10.0.0.0/23"))
+	set2.RemoveNet(TenOne24)
+	set2.Remove(Ten24Router)
+	set2.Insert(Ten24Router)
+
+	assert.Equal(t, set1.Hash(), set2.Hash())
+	assert.NotEqual(t, empty.Hash(), set1.Hash())
+
+	set2.Remove(Ten24Router)
+	assert.NotEqual(t, set1.Hash(), set2.Hash())
+
+	// The same addresses in a different family must not collide
+	v4, v6 := &IPSet{}, &IPSet{}
+	v4.Insert(ParseIP("10.0.0.1"))
+	v6.Insert(ParseIP("::ffff:10.0.0.1"))
+	assert.NotEqual(t, v4.Hash(), v6.Hash())
+}
+
+func TestIPSetString(t *testing.T) {
+	var nilSet *IPSet
+	assert.Equal(t, "[]", nilSet.String())
+	assert.Equal(t, "[]", (&IPSet{}).String())
+
+	s := &IPSet{}
+	s.InsertNet(Ten24)
+	s.InsertNet(TenOne24)
+	s.InsertNet(parse("192.168.0.248/29"))
+	assert.Equal(t, []string{"10.0.0.0/23", "192.168.0.248/29"}, s.Strings())
+	assert.Equal(t, "[10.0.0.0/23 192.168.0.248/29]", s.String())
+
+	var stringer fmt.Stringer = s
+	assert.Equal(t, "[10.0.0.0/23 192.168.0.248/29]", stringer.String())
+}
+
+func TestIPSetFormat(t *testing.T) {
+	s := &IPSet{}
+	s.InsertNet(Ten24)
+	s.Insert(ParseIP("10.0.1.0"))
+	s.InsertNet(V6Net1)
+
+	assert.Equal(t, "[10.0.0.0/24 10.0.1.0/32 2001:db8:1234:abcd::/64]", fmt.Sprintf("%s", s))
+	assert.Equal(t, "[10.0.0.0/24 10.0.1.0/32 2001:db8:1234:abcd::/64]", fmt.Sprintf("%v", s))
+	assert.Equal(t, `"[10.0.0.0/24 10.0.1.0/32 2001:db8:1234:abcd::/64]"`, fmt.Sprintf("%q", s))
+	assert.Equal(t, "[[10.0.0.0,10.0.1.0] [2001:db8:1234:abcd::,2001:db8:1234:abcd:ffff:ffff:ffff:ffff]]", fmt.Sprintf("%r", s))
+	assert.Equal(t, "%!d(*netaddr.IPSet=[10.0.0.0/24 10.0.1.0/32 2001:db8:1234:abcd::/64])", fmt.Sprintf("%d", s))
+	assert.Equal(t, "set: [] []", fmt.Sprintf("set: %v %r", &IPSet{}, &IPSet{}))
+
+	// A nil set formats like String
+	var nilSet *IPSet
+	assert.Equal(t, nilSet.String(), fmt.Sprintf("%v", nilSet))
+	assert.Equal(t, "[] [] []", fmt.Sprintf("%s %v %r", nilSet, nilSet, nilSet))
+
+	// Sets declared as values format through their address
+	value := IPSet{}
+	value.InsertNet(Ten24)
+	assert.Equal(t, "[10.0.0.0/24] [10.0.0.0/24]", fmt.Sprintf("%s %+v", &value, &value))
+
+	// Width and flags apply to the whole list
+	assert.Equal(t, "        []", fmt.Sprintf("%10s", &IPSet{}))
+	assert.Equal(t, "[]        |", fmt.Sprintf("%-10v|", &IPSet{}))
+	assert.Equal(t, `      "[]"`, fmt.Sprintf("%10q", &IPSet{}))
+	assert.Equal(t, "[10.0.0.0/24 10", fmt.Sprintf("%.15s", s))
+	assert.Equal(t, "[[10.0.0.0,10.0.1.0] [2001:db8:1234:abcd::,2001:db8:1234:abcd:ffff:ffff:ffff:ffff]]", fmt.Sprintf("%5r", s))
+}
+
+func TestIPSetSplitFragmented(t *testing.T) {
+	// Every other address so that no two are in the same range
+	s := &IPSet{}
+	for i := uint32(0); i < 4000; i += 2 {
+		s.Insert(Uint32ToIPv4(167772160 + i))
+	}
+
+	var parts []*IPSet
+	allocs := testing.AllocsPerRun(1, func() {
+		parts = s.Split(500)
+	})
+	for _, p := range parts {
+		assert.Equal(t, big.NewInt(4), p.tree.size())
+	}
+	assert.Equal(t, "[10.0.15.152/32 10.0.15.154/32 10.0.15.156/32 10.0.15.158/32]", parts[499].String())
+
+	// Visiting every range for every part takes millions of allocations
+	assert.True(t, allocs < 1000000, "allocs=%f", allocs)
+}
+
+func TestIPSetSplit(t *testing.T) {
+	s := &IPSet{}
+	s.InsertNet(parse("10.0.0.0/24"))
+	s.InsertNet(parse("10.0.2.0/25"))
+	s.Insert(ParseIP("10.0.3.7"))
+	s.InsertNet(parse("2001:db8::/126"))
+
+	for _, k := range []int{1, 2, 3, 7, 500} {
+		parts := s.Split(k)
+		assert.Equal(t, k, len(parts))
+
+		union := &IPSet{}
+		min, max := s.tree.size(), big.NewInt(0)
+		for i, p := range parts {
+			assert.Equal(t, []error{}, p.tree.validate())
+			size := p.tree.size()
+			if size.Cmp(min) < 0 {
+				min = size
+			}
+			if size.Cmp(max) > 0 {
+				max = size
+			}
+			for _, q := range parts[i+1:] {
+				assert.Equal(t, big.NewInt(0), p.Intersection(q).tree.size())
+			}
+			union = union.Union(p)
+		}
+		assert.True(t, big.NewInt(0).Sub(max, min).Cmp(big.NewInt(1)) <= 0, "k=%d min=%s max=%s", k, min, max)
+		assert.Equal(t, s.Hash(), union.Hash())
+	}
+
+	parts := s.Split(2)
+	assert.Equal(t, "[10.0.0.0/25 10.0.0.128/26 10.0.0.192/31]", parts[0].String())
+	assert.Equal(t, "[10.0.0.194/31 10.0.0.196/30 10.0.0.200/29 10.0.0.208/28 10.0.0.224/27 10.0.2.0/25 10.0.3.7/32 2001:db8::/126]", parts[1].String())
+
+	assert.Nil(t, s.Split(0))
+	assert.Equal(t, "[[] []]", fmt.Sprintf("%s", (&IPSet{}).Split(2)))
+}
+
+func TestGetNetworksWithin(t *testing.T) {
+	s := &IPSet{}
+	s.InsertNet(parse("10.0.0.0/7"))
+	s.InsertNet(parse("192.168.0.0/22"))
+	s.InsertNet(parse("192.168.8.0/25"))
+	s.InsertNet(parse("192.168.8.128/26"))
+	s.InsertNet(parse("192.168.9.1/32"))
+	s.InsertNet(V6Net1)
+
+	_, err := s.GetNetworksWithin(net.IPv4len, 8, 24, RejectLongPrefixes)
+	assert.True(t, errors.Is(err, ErrInvalidPrefixLength))
+
+	networks, err := s.GetNetworksWithin(net.IPv4len, 8, 24, WidenLongPrefixes)
+	assert.Nil(t, err)
+	assert.Equal(t, "[10.0.0.0/8 11.0.0.0/8 192.168.0.0/22 192.168.8.0/23]", fmt.Sprintf("%s", networks))
+
+	networks, err = s.GetNetworksWithin(net.IPv4len, 8, 32, RejectLongPrefixes)
+	assert.Nil(t, err)
+	assert.Equal(t, "[10.0.0.0/8 11.0.0.0/8 192.168.0.0/22 192.168.8.0/25 192.168.8.128/26 192.168.9.1/32]", fmt.Sprintf("%s", networks))
+
+	networks, err = s.GetNetworksWithin(net.IPv4len, 23, 24, WidenLongPrefixes)
+	assert.Nil(t, err)
+	assert.Equal(t, 65539, len(networks))
+
+	networks, err = s.GetNetworksWithin(net.IPv6len, 66, 128, RejectLongPrefixes)
+	assert.Nil(t, err)
+	assert.Equal(t, "[2001:db8:1234:abcd::/66 2001:db8:1234:abcd:4000::/66 2001:db8:1234:abcd:8000::/66 2001:db8:1234:abcd:c000::/66]", fmt.Sprintf("%s", networks))
+
+	networks, err = s.GetNetworksWithin(net.IPv6len, 0, 48, WidenLongPrefixes)
+	assert.Nil(t, err)
+	assert.Equal(t, "[2001:db8:1234::/48]", fmt.Sprintf("%s", networks))
+}
+
+func TestGetNetworksWithinErrors(t *testing.T) {
+	s := &IPSet{}
+	for _, tc := range []struct {
+		family, minLen, maxLen int
+		err                    error
+	}{
+		{0, 8, 24, ErrFamilyMismatch},
+		{net.IPv4len, 25, 24, ErrInvalidPrefixLength},
+		{net.IPv4len, -1, 24, ErrInvalidPrefixLength},
+		{net.IPv4len, 8, 33, ErrInvalidPrefixLength},
+		{net.IPv6len, 8, 129, ErrInvalidPrefixLength},
+	} {
+		networks, err := s.GetNetworksWithin(tc.family, tc.minLen, tc.maxLen, RejectLongPrefixes)
+		assert.True(t, errors.Is(err, tc.err), err)
+		assert.Nil(t, networks)
+	}
+}
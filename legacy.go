@@ -0,0 +1,110 @@
+package netaddr
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseIPLegacy parses an IPv4 address the way the C library's inet_aton does.
+// In addition to the usual dotted decimal form, it accepts hex parts with a 0x
+// prefix, octal parts with a leading 0 and fewer than four parts where the
+// last part fills the remaining bytes. For example, "127.1", "0x7f.1",
+// "0177.0.0.1" and "2130706433" are all 127.0.0.1. IPv6 addresses are parsed
+// as with ParseIPErr. Use String on the result to normalize the address.
+//
+// Beware that ParseIP and many other tools read these forms differently or
+// not at all. Use ParseIPLegacyStrict to detect them.
+func ParseIPLegacy(address string) (net.IP, error) {
+	ip, _, err := parseIPLegacy(address)
+	return ip, err
+}
+
+// ParseIPLegacyStrict is like ParseIPLegacy except that it returns a
+// *ParseError wrapping ErrAmbiguousAddress for any address which is not in the
+// plain dotted decimal form, e.g. because it has leading zeros, hex parts or
+// fewer than four parts. The position points at the first offending part.
+func ParseIPLegacyStrict(address string) (net.IP, error) {
+	ip, ambiguousPos, err := parseIPLegacy(address)
+	if err != nil {
+		return nil, err
+	}
+	if ambiguousPos >= 0 {
+		return nil, &ParseError{Input: address, Pos: ambiguousPos, Err: ErrAmbiguousAddress}
+	}
+	return ip, nil
+}
+
+// parseIPLegacy does the work for ParseIPLegacy. It also returns the position
+// of the first part which is not plain dotted decimal or -1 if there is none.
+func parseIPLegacy(address string) (ip net.IP, ambiguousPos int, err error) {
+	ambiguousPos = -1
+	if strings.Contains(address, ":") {
+		ip, err = ParseIPErr(address)
+		return
+	}
+
+	parts := strings.Split(address, ".")
+	if len(parts) > 4 {
+		return nil, -1, &ParseError{Input: address, Pos: len(strings.Join(parts[:4], ".")), Err: ErrInvalidAddress}
+	}
+
+	values := make([]uint64, len(parts))
+	start := 0
+	for i, part := range parts {
+		value, ambiguous, ok := parseLegacyPart(part)
+		if !ok {
+			return nil, -1, &ParseError{Input: address, Pos: start, Err: ErrInvalidAddress}
+		}
+		if ambiguous && ambiguousPos < 0 {
+			ambiguousPos = start
+		}
+
+		// All but the last part are single bytes. The last part fills the
+		// rest of the address.
+		max := uint64(255)
+		if i == len(parts)-1 {
+			max = 1<<uint(8*(4-i)) - 1
+		}
+		if value > max {
+			return nil, -1, &ParseError{Input: address, Pos: start, Err: ErrInvalidAddress}
+		}
+		values[i] = value
+		start += len(part) + 1
+	}
+	if len(parts) < 4 && ambiguousPos < 0 {
+		ambiguousPos = start - len(parts[len(parts)-1]) - 1
+	}
+
+	ip = NewIP(net.IPv4len)
+	for i, value := range values[:len(values)-1] {
+		ip[i] = byte(value)
+	}
+	last := values[len(values)-1]
+	for i := net.IPv4len - 1; i >= len(values)-1; i-- {
+		ip[i] = byte(last)
+		last >>= 8
+	}
+	return
+}
+
+// parseLegacyPart parses one part of an inet_aton address. A part is ambiguous
+// if it is hex or has a leading zero.
+func parseLegacyPart(part string) (value uint64, ambiguous, ok bool) {
+	base := 10
+	digits := part
+	switch {
+	case strings.HasPrefix(part, "0x") || strings.HasPrefix(part, "0X"):
+		base, digits = 16, part[2:]
+	case len(part) > 1 && part[0] == '0':
+		base, digits = 8, part[1:]
+	}
+	if digits == "" || strings.ContainsAny(digits[:1], "+-") {
+		return 0, false, false
+	}
+	value, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return 0, false, false
+	}
+	return value, base != 10, true
+}
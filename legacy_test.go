@@ -0,0 +1,94 @@
+package netaddr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPLegacy(t *testing.T) {
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"127.0.0.1", "127.0.0.1"},
+		{"127.1", "127.0.0.1"},
+		{"10.1", "10.0.0.1"},
+		{"10.1.2", "10.1.0.2"},
+		{"10.1.515", "10.1.2.3"},
+		{"0x7f.1", "127.0.0.1"},
+		{"0X7F.0.0.1", "127.0.0.1"},
+		{"0177.0.0.1", "127.0.0.1"},
+		{"2130706433", "127.0.0.1"},
+		{"0x7f000001", "127.0.0.1"},
+		{"017700000001", "127.0.0.1"},
+		{"0", "0.0.0.0"},
+		{"00", "0.0.0.0"},
+		{"4294967295", "255.255.255.255"},
+		{"2001:db8::1", "2001:db8::1"},
+	} {
+		ip, err := ParseIPLegacy(tc.in)
+		if assert.Nil(t, err, tc.in) {
+			assert.Equal(t, ParseIP(tc.result), ip, tc.in)
+		}
+	}
+}
+
+func TestParseIPLegacyErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+	}{
+		{"", 0},
+		{"1.2.3.4.5", 7},
+		{"1.2.3.256", 6},
+		{"1.256.3", 2},
+		{"4294967296", 0},
+		{"0x", 0},
+		{"08.1.1.1", 0},
+		{"1.2.3.0xg", 6},
+		{"1..2.3", 2},
+		{"1.2.3.-4", 6},
+		{"1.2.3.+4", 6},
+		{" 1.2.3.4", 0},
+		{"2001:db8::g", 10},
+	} {
+		ip, err := ParseIPLegacy(tc.in)
+		assert.Nil(t, ip, tc.in)
+		assert.Equal(t, &ParseError{Input: tc.in, Pos: tc.pos, Err: ErrInvalidAddress}, err, tc.in)
+	}
+}
+
+func TestParseIPLegacyStrict(t *testing.T) {
+	ip, err := ParseIPLegacyStrict("127.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("127.0.0.1"), ip)
+
+	ip, err = ParseIPLegacyStrict("0.0.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("0.0.0.0"), ip)
+
+	ip, err = ParseIPLegacyStrict("2001:db8::1")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("2001:db8::1"), ip)
+
+	for _, tc := range []struct {
+		in  string
+		pos int
+	}{
+		{"127.1", 4},
+		{"127.0.1", 6},
+		{"2130706433", 0},
+		{"0x7f.0.0.1", 0},
+		{"127.0.0.01", 8},
+		{"10.010.0.1", 3},
+		{"10.1.0x0.1", 5},
+	} {
+		ip, err := ParseIPLegacyStrict(tc.in)
+		assert.Nil(t, ip, tc.in)
+		assert.Equal(t, &ParseError{Input: tc.in, Pos: tc.pos, Err: ErrAmbiguousAddress}, err, tc.in)
+	}
+
+	_, err = ParseIPLegacyStrict("1.2.3.256")
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+}
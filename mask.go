@@ -0,0 +1,79 @@
+package netaddr
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+)
+
+// PrefixLenFromMask returns the number of leading ones in the given netmask,
+// e.g. 24 for 255.255.255.0. It returns an error wrapping ErrFamilyMismatch if
+// the mask isn't 4 or 16 bytes long and an error wrapping ErrNonContiguousMask
+// if it has zeros between its ones.
+func PrefixLenFromMask(mask net.IPMask) (int, error) {
+	if len(mask) != net.IPv4len && len(mask) != net.IPv6len {
+		return 0, fmt.Errorf("bad netmask length %d: %w", len(mask), ErrFamilyMismatch)
+	}
+	ones, bits := mask.Size()
+	if bits == 0 {
+		return 0, fmt.Errorf("%s: %w", mask, ErrNonContiguousMask)
+	}
+	return ones, nil
+}
+
+// MaskFromPrefixLen returns the netmask with the given number of leading ones.
+// The family must be net.IPv4len or net.IPv6len and determines the size of
+// the mask; any other family gives an error wrapping ErrFamilyMismatch. It
+// returns an error wrapping ErrInvalidPrefixLength if the prefix length is
+// negative or too long for the family.
+func MaskFromPrefixLen(family, bits int) (net.IPMask, error) {
+	if err := checkFamily(family); err != nil {
+		return nil, err
+	}
+	if bits < 0 || bits > 8*family {
+		return nil, fmt.Errorf("/%d for a %d byte address: %w", bits, family, ErrInvalidPrefixLength)
+	}
+	return net.CIDRMask(bits, 8*family), nil
+}
+
+// HostMask returns the inverse of the network's netmask, sometimes called a
+// wildcard mask, e.g. 0.0.0.255 for a /24
+func HostMask(n *net.IPNet) net.IPMask {
+	mask := make(net.IPMask, len(n.Mask))
+	for i, b := range n.Mask {
+		mask[i] = ^b
+	}
+	return mask
+}
+
+// IsValidNetmask returns true iff the given mask is 4 or 16 bytes long and all
+// of its ones come before all of its zeros
+func IsValidNetmask(mask net.IPMask) bool {
+	_, err := PrefixLenFromMask(mask)
+	return err == nil
+}
+
+// PrefixForHostCount returns the longest prefix length, and so the smallest
+// network, which holds the given number of addresses. The count includes the
+// network and broadcast addresses, so 254 usable IPv4 hosts need a count of
+// 256 and get a /24. The family must be net.IPv4len or net.IPv6len; any other
+// family gives an error wrapping ErrFamilyMismatch. It returns an error
+// wrapping ErrInvalidPrefixLength if no network in the family is big enough.
+func PrefixForHostCount(family int, hosts *big.Int) (int, error) {
+	if err := checkFamily(family); err != nil {
+		return 0, err
+	}
+	if hosts.Sign() < 0 {
+		return 0, fmt.Errorf("negative host count: %s", hosts)
+	}
+
+	// The number of host bits needed is the bit length of hosts - 1
+	hostBits := 0
+	if hosts.Sign() > 0 {
+		hostBits = big.NewInt(0).Sub(hosts, big.NewInt(1)).BitLen()
+	}
+	if hostBits > 8*family {
+		return 0, fmt.Errorf("%s hosts in a %d byte address: %w", hosts, family, ErrInvalidPrefixLength)
+	}
+	return 8*family - hostBits, nil
+}
@@ -0,0 +1,133 @@
+package netaddr
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixLenFromMask(t *testing.T) {
+	for _, tc := range []struct {
+		mask net.IPMask
+		ones int
+	}{
+		{net.IPv4Mask(255, 255, 255, 0), 24},
+		{net.IPv4Mask(0, 0, 0, 0), 0},
+		{net.IPv4Mask(255, 255, 255, 255), 32},
+		{net.IPv4Mask(255, 255, 240, 0), 20},
+		{net.CIDRMask(64, 128), 64},
+		{net.CIDRMask(128, 128), 128},
+	} {
+		ones, err := PrefixLenFromMask(tc.mask)
+		assert.Nil(t, err, tc.mask.String())
+		assert.Equal(t, tc.ones, ones, tc.mask.String())
+	}
+}
+
+func TestPrefixLenFromMaskErrors(t *testing.T) {
+	_, err := PrefixLenFromMask(net.IPv4Mask(255, 0, 255, 0))
+	assert.True(t, errors.Is(err, ErrNonContiguousMask))
+
+	_, err = PrefixLenFromMask(net.IPv4Mask(0, 0, 0, 255))
+	assert.True(t, errors.Is(err, ErrNonContiguousMask))
+
+	for _, mask := range []net.IPMask{nil, {}, {255, 255, 0}, {255, 0, 255}, {255, 255, 255, 255, 0}} {
+		_, err = PrefixLenFromMask(mask)
+		assert.True(t, errors.Is(err, ErrFamilyMismatch), mask.String())
+		assert.False(t, errors.Is(err, ErrNonContiguousMask), mask.String())
+	}
+}
+
+func TestMaskFromPrefixLen(t *testing.T) {
+	mask, err := MaskFromPrefixLen(net.IPv4len, 24)
+	assert.Nil(t, err)
+	assert.Equal(t, net.IPv4Mask(255, 255, 255, 0), mask)
+
+	mask, err = MaskFromPrefixLen(net.IPv4len, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, net.IPv4Mask(0, 0, 0, 0), mask)
+
+	mask, err = MaskFromPrefixLen(net.IPv6len, 64)
+	assert.Nil(t, err)
+	assert.Equal(t, net.CIDRMask(64, 128), mask)
+
+	for _, tc := range []struct {
+		family, bits int
+	}{
+		{net.IPv4len, -1},
+		{net.IPv4len, 33},
+		{net.IPv6len, 129},
+	} {
+		mask, err = MaskFromPrefixLen(tc.family, tc.bits)
+		assert.Nil(t, mask)
+		assert.True(t, errors.Is(err, ErrInvalidPrefixLength))
+	}
+
+	mask, err = MaskFromPrefixLen(5, 8)
+	assert.Nil(t, mask)
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+}
+
+func TestHostMask(t *testing.T) {
+	assert.Equal(t, net.IPv4Mask(0, 0, 0, 255), HostMask(parse("10.0.0.0/24")))
+	assert.Equal(t, net.IPv4Mask(0, 0, 15, 255), HostMask(parse("10.0.0.0/20")))
+	assert.Equal(t, net.IPv4Mask(255, 255, 255, 255), HostMask(parse("0.0.0.0/0")))
+	assert.Equal(t, net.IPv4Mask(0, 0, 0, 0), HostMask(parse("10.0.0.1/32")))
+	assert.Equal(t, net.IPMask(ParseIP("::ffff:ffff:ffff:ffff")), HostMask(parse("2001:db8::/64")))
+}
+
+func TestIsValidNetmask(t *testing.T) {
+	assert.True(t, IsValidNetmask(net.IPv4Mask(255, 255, 255, 0)))
+	assert.True(t, IsValidNetmask(net.IPv4Mask(0, 0, 0, 0)))
+	assert.True(t, IsValidNetmask(net.CIDRMask(48, 128)))
+	assert.False(t, IsValidNetmask(net.IPv4Mask(255, 0, 255, 0)))
+	assert.False(t, IsValidNetmask(net.IPv4Mask(0, 0, 0, 255)))
+	assert.False(t, IsValidNetmask(net.IPMask{255, 255}))
+	assert.False(t, IsValidNetmask(nil))
+}
+
+func TestPrefixForHostCount(t *testing.T) {
+	for _, tc := range []struct {
+		family int
+		hosts  int64
+		prefix int
+	}{
+		{net.IPv4len, 0, 32},
+		{net.IPv4len, 1, 32},
+		{net.IPv4len, 2, 31},
+		{net.IPv4len, 3, 30},
+		{net.IPv4len, 4, 30},
+		{net.IPv4len, 256, 24},
+		{net.IPv4len, 257, 23},
+		{net.IPv4len, 4294967296, 0},
+		{net.IPv6len, 1, 128},
+		{net.IPv6len, 4294967297, 95},
+	} {
+		prefix, err := PrefixForHostCount(tc.family, big.NewInt(tc.hosts))
+		assert.Nil(t, err, tc.hosts)
+		assert.Equal(t, tc.prefix, prefix, tc.hosts)
+	}
+
+	all := big.NewInt(0).Lsh(big.NewInt(1), 128)
+	prefix, err := PrefixForHostCount(net.IPv6len, all)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, prefix)
+}
+
+func TestPrefixForHostCountErrors(t *testing.T) {
+	_, err := PrefixForHostCount(net.IPv4len, big.NewInt(4294967297))
+	assert.True(t, errors.Is(err, ErrInvalidPrefixLength))
+
+	tooMany := big.NewInt(0).Lsh(big.NewInt(1), 128)
+	_, err = PrefixForHostCount(net.IPv6len, tooMany.Add(tooMany, big.NewInt(1)))
+	assert.True(t, errors.Is(err, ErrInvalidPrefixLength))
+
+	_, err = PrefixForHostCount(net.IPv4len, big.NewInt(-1))
+	assert.NotNil(t, err)
+
+	_, err = PrefixForHostCount(5, big.NewInt(1))
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+}
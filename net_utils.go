@@ -2,10 +2,12 @@ package netaddr
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math/big"
 	"net"
 	"strings"
+	"unicode"
 )
 
 // NetSize returns the size of the given IPNet in terms of the number of
@@ -25,18 +27,46 @@ func ParseIP(address string) net.IP {
 	return net.ParseIP(address).To4()
 }
 
+// ParseIPErr is like ParseIP except that it returns a *ParseError wrapping
+// ErrInvalidAddress instead of nil when the address cannot be parsed.
+func ParseIPErr(address string) (net.IP, error) {
+	ip := ParseIP(address)
+	if ip == nil {
+		return nil, &ParseError{Input: address, Pos: invalidAddressPos(address), Err: ErrInvalidAddress}
+	}
+	return ip, nil
+}
+
 // ParseCIDR is like net.ParseCIDR except that it parses IPv4 addresses as 4
 // byte addresses instead of 16-byte mapped IPv6 addresses. Much like ParseIP.
+// Errors are returned as *ParseError wrapping ErrInvalidAddress or
+// ErrInvalidPrefixLength.
 func ParseCIDR(cidr string) (net.IP, *net.IPNet, error) {
-	ip, ipNet, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return net.IP{}, nil, err
+	slash := strings.LastIndex(cidr, "/")
+	if slash < 0 {
+		return net.IP{}, nil, &ParseError{Input: cidr, Pos: len(cidr), Err: ErrInvalidPrefixLength}
 	}
 
-	if strings.Contains(cidr, ":") {
-		return ip, ipNet, nil
+	ip := ParseIP(cidr[:slash])
+	if ip == nil {
+		return net.IP{}, nil, &ParseError{Input: cidr, Pos: invalidAddressPos(cidr[:slash]), Err: ErrInvalidAddress}
+	}
+
+	bits := 8 * len(ip)
+	ones := 0
+	prefix := cidr[slash+1:]
+	for i, c := range prefix {
+		if c < '0' || c > '9' || ones*10+int(c-'0') > bits {
+			return net.IP{}, nil, &ParseError{Input: cidr, Pos: slash + 1 + i, Err: ErrInvalidPrefixLength}
+		}
+		ones = ones*10 + int(c-'0')
+	}
+	if prefix == "" {
+		return net.IP{}, nil, &ParseError{Input: cidr, Pos: slash + 1, Err: ErrInvalidPrefixLength}
 	}
-	return ip.To4(), ipNet, nil
+
+	mask := net.CIDRMask(ones, bits)
+	return ip, &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
 }
 
 // ParseCIDRToNet is like ParseCIDR except that it only returns one *net.IPNet
@@ -56,18 +86,90 @@ func ParseCIDRToNet(cidr string) (*net.IPNet, error) {
 // ParseNet parses an IP network from a CIDR. Unlike net.ParseCIDR, it does not
 // allow a CIDR where the host part is non-zero. For example, the following
 // CIDRs will result in an error: 203.0.113.1/24, 2001:db8::1/64, 10.0.20.0/20
+// Errors are returned as *ParseError like ParseCIDR. A non-zero host part
+// results in ErrHostBitsSet with the position of the first octet or group
+// which has host bits set.
 func ParseNet(cidr string) (parsed *net.IPNet, err error) {
-	ip, parsed, err := net.ParseCIDR(cidr)
+	ip, parsed, err := ParseCIDR(cidr)
 	if err != nil {
 		return nil, err
 	}
 	if !ip.Equal(parsed.IP) {
-		err = fmt.Errorf("Host part is not zero")
+		slash := strings.LastIndex(cidr, "/")
+		err = &ParseError{Input: cidr, Pos: hostBitsPos(cidr[:slash], ip, parsed.Mask), Err: ErrHostBitsSet}
 		return nil, err
 	}
 	return
 }
 
+// ParseNetFlexible is like ParseNet except that it also accepts IPv4 networks
+// with a dotted netmask after either a slash or whitespace, e.g.
+// 10.0.0.0/255.255.255.0 or 10.0.0.0 255.255.255.0, as found in many device
+// configs. Surrounding whitespace is ignored. A mask with zeros between its
+// ones results in ErrNonContiguousMask.
+func ParseNetFlexible(str string) (*net.IPNet, error) {
+	trimmed := strings.TrimLeftFunc(str, unicode.IsSpace)
+	n, err := parseNetFlexible(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+	if err != nil {
+		return nil, rebaseError(err, str, len(str)-len(trimmed))
+	}
+	return n, nil
+}
+
+// parseNetFlexible implements ParseNetFlexible for a string without
+// surrounding whitespace
+func parseNetFlexible(str string) (*net.IPNet, error) {
+	sep := strings.IndexAny(str, "/ \t")
+	if sep < 0 {
+		return ParseNet(str)
+	}
+	maskPos := sep + 1
+	if str[sep] == '/' {
+		if !strings.Contains(str[maskPos:], ".") {
+			return ParseNet(str)
+		}
+	} else {
+		for maskPos < len(str) && (str[maskPos] == ' ' || str[maskPos] == '\t') {
+			maskPos++
+		}
+	}
+
+	ip := ParseIP(str[:sep])
+	if ip == nil {
+		return nil, &ParseError{Input: str, Pos: invalidAddressPos(str[:sep]), Err: ErrInvalidAddress}
+	}
+	if len(ip) != net.IPv4len {
+		return nil, &ParseError{Input: str, Pos: maskPos, Err: ErrFamilyMismatch}
+	}
+
+	maskIP := ParseIP(str[maskPos:])
+	if maskIP == nil {
+		return nil, &ParseError{Input: str, Pos: maskPos + invalidAddressPos(str[maskPos:]), Err: ErrInvalidAddress}
+	}
+	if len(maskIP) != net.IPv4len {
+		return nil, &ParseError{Input: str, Pos: maskPos, Err: ErrFamilyMismatch}
+	}
+	mask := net.IPMask(maskIP)
+	if !IsValidNetmask(mask) {
+		return nil, &ParseError{Input: str, Pos: maskPos, Err: ErrNonContiguousMask}
+	}
+
+	if !ip.Equal(ip.Mask(mask)) {
+		return nil, &ParseError{Input: str, Pos: hostBitsPos(str[:sep], ip, mask), Err: ErrHostBitsSet}
+	}
+	return &net.IPNet{IP: ip, Mask: mask}, nil
+}
+
+// NetmaskString returns the given IPv4 network as the network address and
+// dotted netmask separated by a space, e.g. "10.0.0.0 255.255.255.0", which
+// ParseNetFlexible accepts. IPv6 networks are returned in CIDR notation.
+func NetmaskString(n *net.IPNet) string {
+	if len(n.IP) != net.IPv4len || len(n.Mask) != net.IPv4len {
+		return n.String()
+	}
+	return NetworkAddr(n).String() + " " + net.IP(n.Mask).String()
+}
+
 // NewIP returns a new IP with the given size. The size must be 4 for IPv4 and
 // 16 for IPv6.
 func NewIP(size int) net.IP {
@@ -168,6 +270,16 @@ func divideNetInHalf(n *net.IPNet) (a, b *net.IPNet) {
 	return
 }
 
+// splitNet returns the given network divided into networks with a prefix
+// length of at least minLen. The networks are sorted by IP.
+func splitNet(n *net.IPNet, minLen int) []*net.IPNet {
+	if ones, _ := n.Mask.Size(); ones >= minLen {
+		return []*net.IPNet{n}
+	}
+	first, second := divideNetInHalf(n)
+	return append(splitNet(first, minLen), splitNet(second, minLen)...)
+}
+
 // canCombineNets returns true if the two networks, a and b, can be combined
 // into one larger cidr twice the size. If true, it returns the combined
 // network.
@@ -228,6 +340,85 @@ func decrementIP(ip net.IP) (result net.IP) {
 	return
 }
 
+// rangeToNets returns the smallest list of CIDRs that exactly covers the IPs
+// from first to last inclusive. It returns nil if first and last are not the
+// same valid size or first comes after last.
+func rangeToNets(first, last net.IP) (nets []*net.IPNet) {
+	if len(first) != len(last) || (len(first) != net.IPv4len && len(first) != net.IPv6len) || IPLessThan(last, first) {
+		return nil
+	}
+
+	bits := 8 * len(first)
+	for {
+		// Find the largest CIDR that starts at first and doesn't go past last
+		var n *net.IPNet
+		for ones := 0; ones <= bits; ones++ {
+			n = &net.IPNet{IP: first, Mask: net.CIDRMask(ones, bits)}
+			if NetworkAddr(n).Equal(first) && !IPLessThan(last, BroadcastAddr(n)) {
+				break
+			}
+		}
+		nets = append(nets, n)
+
+		broadcast := BroadcastAddr(n)
+		if broadcast.Equal(last) {
+			return
+		}
+		first = incrementIP(broadcast)
+	}
+}
+
+// IPToInt returns the given IP as an unsigned integer, e.g. 10.0.0.1 is
+// 167772161. The bytes of the IP are used as is so 16 byte IPv4 addresses
+// return the IPv4-mapped IPv6 value.
+func IPToInt(ip net.IP) *big.Int {
+	return big.NewInt(0).SetBytes(ip)
+}
+
+// IntToIP returns the IP with the given integer value. The family must be
+// net.IPv4len or net.IPv6len and determines the size of the IP. It returns an
+// error if the integer is negative or too large for the family, or an error
+// wrapping ErrFamilyMismatch for any other family.
+func IntToIP(n *big.Int, family int) (net.IP, error) {
+	if err := checkFamily(family); err != nil {
+		return nil, err
+	}
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("negative value for an IP: %s", n)
+	}
+	if n.BitLen() > 8*family {
+		return nil, fmt.Errorf("value too large for a %d byte IP: %s", family, n)
+	}
+	return bigIntToIP(n, family), nil
+}
+
+// IPv4ToUint32 returns the given IPv4 address as an integer without the
+// overhead of big.Int. IPv4 addresses in 16 byte form are accepted. It returns
+// an error wrapping ErrFamilyMismatch for any other IP.
+func IPv4ToUint32(ip net.IP) (uint32, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0, fmt.Errorf("%s is not an IPv4 address: %w", ip, ErrFamilyMismatch)
+	}
+	return binary.BigEndian.Uint32(ip4), nil
+}
+
+// Uint32ToIPv4 returns the 4 byte IPv4 address with the given integer value
+func Uint32ToIPv4(n uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
+
+// bigIntToIP returns the IP of the given size for the given integer. The
+// integer must fit in size bytes.
+func bigIntToIP(n *big.Int, size int) net.IP {
+	ip := NewIP(size)
+	b := n.Bytes()
+	copy(ip[size-len(b):], b)
+	return ip
+}
+
 // expandNet returns a slice containing all of the IPs in the given net up to
 // the given limit
 func expandNet(n *net.IPNet, limit int) []net.IP {
@@ -259,15 +450,7 @@ func expandNet(n *net.IPNet, limit int) []net.IP {
 // 192.169.0.1
 // 2001:db8::
 func IPLessThan(a, b net.IP) bool {
-	if len(a) != len(b) { // ipv6 comes after ipv4
-		return len(a) < len(b)
-	}
-	for i := range a { // go left to right and compare each one
-		if a[i] != b[i] {
-			return a[i] < b[i]
-		}
-	}
-	return false // they are equal
+	return compareIPBytes(a, b) < 0
 }
 
 // IPMin returns the minimum of a and b
@@ -1,6 +1,7 @@
 package netaddr
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -269,3 +270,220 @@ func TestIPv4Net(t *testing.T) {
 	lo, _ := ParseCIDRToNet("127.0.0.1/8")
 	assert.Equal(t, *lo, IPv4Net(127, 0, 0, 1, 8))
 }
+
+func TestRangeToNets(t *testing.T) {
+	for _, tc := range []struct {
+		first, last string
+		result      string
+	}{
+		{"10.0.0.0", "10.0.0.255", "[10.0.0.0/24]"},
+		{"10.0.0.1", "10.0.0.1", "[10.0.0.1/32]"},
+		{"10.0.0.1", "10.0.0.10", "[10.0.0.1/32 10.0.0.2/31 10.0.0.4/30 10.0.0.8/31 10.0.0.10/32]"},
+		{"0.0.0.0", "255.255.255.255", "[0.0.0.0/0]"},
+		{"255.255.255.254", "255.255.255.255", "[255.255.255.254/31]"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "[::/0]"},
+		{"2001:db8::", "2001:db8::1:0", "[2001:db8::/112 2001:db8::1:0/128]"},
+	} {
+		nets := rangeToNets(ParseIP(tc.first), ParseIP(tc.last))
+		assert.Equal(t, tc.result, fmt.Sprintf("%s", nets))
+	}
+
+	assert.Nil(t, rangeToNets(ParseIP("10.0.0.2"), ParseIP("10.0.0.1")))
+	assert.Nil(t, rangeToNets(ParseIP("10.0.0.1"), ParseIP("2001:db8::")))
+}
+
+func TestIPToInt(t *testing.T) {
+	assert.Equal(t, "0", IPToInt(ParseIP("0.0.0.0")).String())
+	assert.Equal(t, "167772161", IPToInt(ParseIP("10.0.0.1")).String())
+	assert.Equal(t, "18446744073709551616", IPToInt(ParseIP("::1:0:0:0:0")).String())
+
+	assert.Equal(t, ParseIP("10.0.0.1"), bigIntToIP(big.NewInt(167772161), 4))
+	assert.Equal(t, ParseIP("::a00:1"), bigIntToIP(big.NewInt(167772161), 16))
+	assert.Equal(t, ParseIP("0.0.0.0"), bigIntToIP(big.NewInt(0), 4))
+}
+
+func TestParseIPErr(t *testing.T) {
+	ip, err := ParseIPErr("10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("10.0.0.1"), ip)
+
+	ip, err = ParseIPErr("2001:db8::1")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("2001:db8::1"), ip)
+
+	ip, err = ParseIPErr("10.0.0.256")
+	assert.Nil(t, ip)
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+	assert.Equal(t, &ParseError{Input: "10.0.0.256", Pos: 7, Err: ErrInvalidAddress}, err)
+}
+
+func TestParseCIDRTypedErrors(t *testing.T) {
+	for _, tc := range []struct {
+		cidr string
+		pos  int
+		err  error
+	}{
+		{"", 0, ErrInvalidPrefixLength},
+		{"10.0.0.1", 8, ErrInvalidPrefixLength},
+		{"10.0.0.1/", 9, ErrInvalidPrefixLength},
+		{"10.0.0.1/33", 10, ErrInvalidPrefixLength},
+		{"10.0.0.1/-1", 9, ErrInvalidPrefixLength},
+		{"10.0.0.1/2x", 10, ErrInvalidPrefixLength},
+		{"2001:db8::/129", 13, ErrInvalidPrefixLength},
+		{"300.1.2.3/24", 0, ErrInvalidAddress},
+		{"10.1.2.x/24", 7, ErrInvalidAddress},
+		{"bogus/24", 0, ErrInvalidAddress},
+	} {
+		_, _, err := ParseCIDR(tc.cidr)
+		assert.Equal(t, &ParseError{Input: tc.cidr, Pos: tc.pos, Err: tc.err}, err, tc.cidr)
+
+		_, err = ParseNet(tc.cidr)
+		assert.Equal(t, &ParseError{Input: tc.cidr, Pos: tc.pos, Err: tc.err}, err, tc.cidr)
+	}
+
+	_, err := ParseNet("10.0.0.1/24")
+	assert.True(t, errors.Is(err, ErrHostBitsSet))
+	assert.Equal(t, `host bits set: "10.0.0.1/24" at position 7`, err.Error())
+
+	for _, tc := range []struct {
+		cidr string
+		pos  int
+	}{
+		{"10.0.20.0/20", 5},
+		{"10.1.0.0/8", 3},
+		{"203.0.113.1/24", 10},
+		{"2001:db8::1/64", 10},
+		{"2001:db8:1::/32", 9},
+	} {
+		_, err = ParseNet(tc.cidr)
+		assert.Equal(t, &ParseError{Input: tc.cidr, Pos: tc.pos, Err: ErrHostBitsSet}, err, tc.cidr)
+	}
+}
+
+func TestParseNetFlexible(t *testing.T) {
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"10.0.0.0/24", "10.0.0.0/24"},
+		{"2001:db8::/64", "2001:db8::/64"},
+		{"10.0.0.0/255.255.255.0", "10.0.0.0/24"},
+		{"10.0.0.0 255.255.255.0", "10.0.0.0/24"},
+		{"10.0.0.0 \t 255.255.0.0", "10.0.0.0/16"},
+		{"0.0.0.0 0.0.0.0", "0.0.0.0/0"},
+		{"10.0.0.1/255.255.255.255", "10.0.0.1/32"},
+		{" 10.0.0.0 255.255.255.0", "10.0.0.0/24"},
+		{"\t\t10.0.0.0\t255.255.255.0", "10.0.0.0/24"},
+		{"  10.0.0.0/24 ", "10.0.0.0/24"},
+		{"10.0.0.0 255.255.255.0\r\n", "10.0.0.0/24"},
+		{"\t2001:db8::/64\n", "2001:db8::/64"},
+	} {
+		n, err := ParseNetFlexible(tc.in)
+		if assert.Nil(t, err, tc.in) {
+			assert.Equal(t, tc.result, n.String())
+			assert.Equal(t, parse(tc.result), n)
+		}
+	}
+}
+
+func TestParseNetFlexibleErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+		err error
+	}{
+		{"10.0.0.0", 8, ErrInvalidPrefixLength},
+		{"10.0.0.0/33", 10, ErrInvalidPrefixLength},
+		{"10.0.0.0 255.0.255.0", 9, ErrNonContiguousMask},
+		{"10.0.0.0/0.0.0.255", 9, ErrNonContiguousMask},
+		{"10.0.0.1 255.255.255.0", 7, ErrHostBitsSet},
+		{"10.0.1.0/255.255.0.0", 5, ErrHostBitsSet},
+		{"10.0.1.0/16", 5, ErrHostBitsSet},
+		{"10.0.0.300 255.255.255.0", 7, ErrInvalidAddress},
+		{"10.0.0.0 255.255.256.0", 17, ErrInvalidAddress},
+		{"10.0.0.0 ", 8, ErrInvalidPrefixLength},
+		{"  10.0.0.1 255.255.255.0", 9, ErrHostBitsSet},
+		{"  10.0.0.0 255.0.255.0 ", 11, ErrNonContiguousMask},
+		{"\t10.0.0.0/33\n", 11, ErrInvalidPrefixLength},
+		{"10.0.0.0 24", 11, ErrInvalidAddress},
+		{"2001:db8:: 255.255.255.0", 11, ErrFamilyMismatch},
+		{"10.0.0.0 ffff::", 9, ErrFamilyMismatch},
+	} {
+		n, err := ParseNetFlexible(tc.in)
+		assert.Nil(t, n)
+		assert.Equal(t, &ParseError{Input: tc.in, Pos: tc.pos, Err: tc.err}, err, tc.in)
+	}
+}
+
+func TestNetmaskString(t *testing.T) {
+	assert.Equal(t, "10.0.0.0 255.255.255.0", NetmaskString(parse("10.0.0.0/24")))
+	assert.Equal(t, "10.0.0.0 255.255.255.0", NetmaskString(parse("10.0.0.1/24")))
+	assert.Equal(t, "0.0.0.0 0.0.0.0", NetmaskString(parse("0.0.0.0/0")))
+	assert.Equal(t, "2001:db8::/64", NetmaskString(parse("2001:db8::/64")))
+
+	for _, in := range []string{"10.0.0.0/8", "192.168.1.128/25"} {
+		n, err := ParseNetFlexible(NetmaskString(parse(in)))
+		assert.Nil(t, err)
+		assert.Equal(t, parse(in), n)
+	}
+}
+
+func TestIntToIP(t *testing.T) {
+	ip, err := IntToIP(big.NewInt(167772161), net.IPv4len)
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("10.0.0.1"), ip)
+
+	ip, err = IntToIP(big.NewInt(0), net.IPv4len)
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("0.0.0.0"), ip)
+
+	ip, err = IntToIP(big.NewInt(4294967295), net.IPv4len)
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("255.255.255.255"), ip)
+
+	ip, err = IntToIP(big.NewInt(167772161), net.IPv6len)
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("::a00:1"), ip)
+
+	max := big.NewInt(0).Lsh(big.NewInt(1), 128)
+	max.Sub(max, big.NewInt(1))
+	ip, err = IntToIP(max, net.IPv6len)
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), ip)
+	assert.Equal(t, max, IPToInt(ip))
+
+	for _, tc := range []struct {
+		n      *big.Int
+		family int
+	}{
+		{big.NewInt(-1), net.IPv4len},
+		{big.NewInt(4294967296), net.IPv4len},
+		{big.NewInt(0).Add(max, big.NewInt(1)), net.IPv6len},
+		{big.NewInt(1), 8},
+	} {
+		ip, err := IntToIP(tc.n, tc.family)
+		assert.Nil(t, ip)
+		assert.NotNil(t, err)
+	}
+
+	_, err = IntToIP(big.NewInt(1), 8)
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+}
+
+func TestIPv4ToUint32(t *testing.T) {
+	n, err := IPv4ToUint32(ParseIP("10.0.0.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(167772161), n)
+
+	n, err = IPv4ToUint32(net.ParseIP("255.255.255.255"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4294967295), n)
+
+	_, err = IPv4ToUint32(ParseIP("2001:db8::1"))
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+	_, err = IPv4ToUint32(nil)
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+
+	assert.Equal(t, ParseIP("10.0.0.1"), Uint32ToIPv4(167772161))
+	assert.Equal(t, ParseIP("0.0.0.0"), Uint32ToIPv4(0))
+	assert.Equal(t, ParseIP("255.255.255.255"), Uint32ToIPv4(4294967295))
+}
@@ -0,0 +1,92 @@
+package netaddr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseSet parses a free-form list of IPs and returns them as an IPSet. Items
+// are separated by commas or whitespace and may be any of the following:
+//
+//	10.0.0.1                  a single IP
+//	10.0.0.0/24               a CIDR; the host part may be non-zero
+//	10.0.0.1-10.0.0.99        a range as accepted by ParseIPRange
+//	10.0.0.1-99               a range ending at a last octet
+//	10.0.*.1-10               a glob where each octet is *, a value or a range
+//
+// Unlike ParseTargets, every range needs both ends, so a stray dash like
+// -10.0.0.1 is an error. Errors are returned as *ParseError with the position
+// of the problem.
+func ParseSet(str string) (*IPSet, error) {
+	set := &IPSet{}
+	if err := insertItems(set, str); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ParseSetLines reads lines from r and parses each one like ParseSet,
+// returning the union of all of them. Blank lines and anything following a #
+// are ignored.
+func ParseSetLines(r io.Reader) (*IPSet, error) {
+	set := &IPSet{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if comment := strings.Index(text, "#"); comment >= 0 {
+			text = text[:comment]
+		}
+		if err := insertItems(set, text); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// insertItems inserts each comma or whitespace separated item in str
+func insertItems(set *IPSet, str string) error {
+	pieces, offsets := splitOffsets(str, ", \t\r\n")
+	for i, piece := range pieces {
+		if err := insertItem(set, piece); err != nil {
+			return rebaseError(err, str, offsets[i])
+		}
+	}
+	return nil
+}
+
+// insertItem inserts the IPs named by a single item
+func insertItem(set *IPSet, item string) error {
+	if strings.Contains(item, "/") {
+		return insertAddressOrCIDR(set, item)
+	}
+
+	// A dash after a complete address makes a range, otherwise it is part of
+	// a glob like 10.0.1-2.*
+	if dash := strings.Index(item, "-"); dash >= 0 {
+		if _, err := ParseIPErr(item[:dash]); err == nil {
+			r, err := ParseIPRange(item)
+			if err != nil {
+				return err
+			}
+			for _, n := range r.CIDRs() {
+				set.InsertNet(n)
+			}
+			return nil
+		}
+	}
+
+	if strings.ContainsAny(item, "-*") {
+		octets, err := parseOctetRanges(item, false)
+		if err != nil {
+			return err
+		}
+		insertOctetRanges(set, octets)
+		return nil
+	}
+	return insertAddressOrCIDR(set, item)
+}
@@ -0,0 +1,90 @@
+package netaddr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSet(t *testing.T) {
+	for _, tc := range []struct {
+		str    string
+		result string
+	}{
+		{"", "[]"},
+		{"10.0.0.1", "[10.0.0.1/32]"},
+		{"10.0.0.1/24", "[10.0.0.0/24]"},
+		{"10.0.0.0-10.0.0.7", "[10.0.0.0/29]"},
+		{"10.0.0.8-15", "[10.0.0.8/29]"},
+		{"10.0.1-2.0-127", "[10.0.1.0/25 10.0.2.0/25]"},
+		{"10.0.1.*", "[10.0.1.0/24]"},
+		{"2001:db8::1", "[2001:db8::1/128]"},
+		{"2001:db8::/127", "[2001:db8::/127]"},
+		{"2001:db8::2-2001:db8::3", "[2001:db8::2/127]"},
+		{"10.0.0.1, 10.0.0.2-3\n\t10.0.0.0/31", "[10.0.0.0/30]"},
+	} {
+		set, err := ParseSet(tc.str)
+		if assert.Nil(t, err, tc.str) {
+			assert.Equal(t, tc.result, set.String(), tc.str)
+			assert.Equal(t, []error{}, set.tree.validate())
+		}
+	}
+}
+
+func TestParseSetErrors(t *testing.T) {
+	for _, tc := range []struct {
+		str string
+		pos int
+		err error
+	}{
+		{"bogus", 0, ErrInvalidAddress},
+		{"10.0.0.256", 7, ErrInvalidAddress},
+		{"10.0.0.1 10.0.0.0/33", 19, ErrInvalidPrefixLength},
+		{"10.0.0.1,10.0.0.9-x", 18, ErrInvalidAddress},
+		{"10.0.0.9-1.2", 12, ErrInvalidAddress},
+		{"10.0.0.1-10.0.0.x", 16, ErrInvalidAddress},
+		{"10.0.0.1 10.0.0.1-2001:db8::1", 18, ErrFamilyMismatch},
+		{"10.0.*.x", 7, ErrInvalidAddress},
+		{"-10.0.0.1", 0, ErrInvalidAddress},
+		{"10.0.0.1 10.0.0.5-", 18, ErrInvalidAddress},
+		{"10.0.0-.1", 5, ErrInvalidAddress},
+		{"10.0.*.-9", 7, ErrInvalidAddress},
+	} {
+		set, err := ParseSet(tc.str)
+		assert.Nil(t, set, tc.str)
+		if assert.IsType(t, &ParseError{}, err, tc.str) {
+			assert.Equal(t, tc.str, err.(*ParseError).Input)
+			assert.Equal(t, tc.pos, err.(*ParseError).Pos, tc.str)
+			assert.True(t, errors.Is(err, tc.err), tc.str)
+		}
+	}
+}
+
+func TestParseSetBadRange(t *testing.T) {
+	set, err := ParseSet("10.0.0.1 10.0.0.9-1")
+	assert.Nil(t, set)
+	assert.EqualError(t, err, `first address comes after last address: invalid IP range: "10.0.0.1 10.0.0.9-1" at position 18`)
+	assert.True(t, errors.Is(err, ErrInvalidRange))
+}
+
+func TestParseSetLines(t *testing.T) {
+	set, err := ParseSetLines(strings.NewReader(`# office
+10.0.0.0/25, 10.0.0.128/25
+
+10.0.1.1-10.0.1.2  # printers
+2001:db8::1
+`))
+	if assert.Nil(t, err) {
+		assert.Equal(t, "[10.0.0.0/24 10.0.1.1/32 10.0.1.2/32 2001:db8::1/128]", set.String())
+	}
+
+	set, err = ParseSetLines(strings.NewReader("10.0.0.1\n\n10.0.0.2 10.0.0.x\n"))
+	assert.Nil(t, set)
+	assert.EqualError(t, err, `line 3: invalid IP address: "10.0.0.2 10.0.0.x" at position 16`)
+	var perr *ParseError
+	if assert.True(t, errors.As(err, &perr)) {
+		assert.Equal(t, 16, perr.Pos)
+	}
+}
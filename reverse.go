@@ -0,0 +1,138 @@
+package netaddr
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	ipv4ReverseSuffix = "in-addr.arpa"
+	ipv6ReverseSuffix = "ip6.arpa"
+	hexDigits         = "0123456789abcdef"
+)
+
+// ReverseName returns the fully qualified reverse DNS name of the given IP,
+// e.g. "4.3.2.1.in-addr.arpa." for 1.2.3.4 or the nibble form under
+// "ip6.arpa." for IPv6. IPv4 addresses in 16 byte form, like those from
+// net.ParseIP, get the in-addr.arpa name. It returns an empty string if the IP
+// is neither 4 nor 16 bytes.
+func ReverseName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return reverseZone(ip, 8*len(ip))
+}
+
+// ParseReverseName returns the IP for the given reverse DNS name. It is the
+// inverse of ReverseName except that the trailing dot is optional and case is
+// ignored. Names which do not have a label for every byte or nibble of the
+// address result in ErrInvalidAddress.
+func ParseReverseName(name string) (net.IP, error) {
+	lower := strings.ToLower(strings.TrimSuffix(name, "."))
+
+	var labels []string
+	var size int
+	switch {
+	case strings.HasSuffix(lower, "."+ipv4ReverseSuffix):
+		labels = strings.Split(strings.TrimSuffix(lower, "."+ipv4ReverseSuffix), ".")
+		size = net.IPv4len
+	case strings.HasSuffix(lower, "."+ipv6ReverseSuffix):
+		labels = strings.Split(strings.TrimSuffix(lower, "."+ipv6ReverseSuffix), ".")
+		size = net.IPv6len
+	default:
+		return nil, &ParseError{Input: name, Pos: 0, Err: fmt.Errorf("not a reverse DNS name: %w", ErrInvalidAddress)}
+	}
+
+	ip := NewIP(size)
+	pos := 0
+	if size == net.IPv4len {
+		if len(labels) != net.IPv4len {
+			return nil, &ParseError{Input: name, Pos: 0, Err: fmt.Errorf("expected %d labels: %w", net.IPv4len, ErrInvalidAddress)}
+		}
+		for i, label := range labels {
+			octet, err := strconv.ParseUint(label, 10, 8)
+			if err != nil || label[0] == '+' || (len(label) > 1 && label[0] == '0') {
+				return nil, &ParseError{Input: name, Pos: pos, Err: ErrInvalidAddress}
+			}
+			ip[net.IPv4len-1-i] = byte(octet)
+			pos += len(label) + 1
+		}
+		return ip, nil
+	}
+
+	if len(labels) != 2*net.IPv6len {
+		return nil, &ParseError{Input: name, Pos: 0, Err: fmt.Errorf("expected %d labels: %w", 2*net.IPv6len, ErrInvalidAddress)}
+	}
+	for i, label := range labels {
+		nibble := strings.Index(hexDigits, label)
+		if len(label) != 1 || nibble < 0 {
+			return nil, &ParseError{Input: name, Pos: pos, Err: ErrInvalidAddress}
+		}
+		n := 2*net.IPv6len - 1 - i
+		if n%2 == 0 {
+			ip[n/2] |= byte(nibble) << 4
+		} else {
+			ip[n/2] |= byte(nibble)
+		}
+		pos += len(label) + 1
+	}
+	return ip, nil
+}
+
+// ReverseZones returns the reverse DNS zones which together cover the given
+// network, in order. A network on an octet boundary for IPv4, or a nibble
+// boundary for IPv6, has exactly one zone. Other IPv6 networks, and IPv4
+// networks of /24 or shorter, are covered by the zones of the next longer
+// boundary, e.g. 10.0.0.0/23 has zones for 10.0.0.0/24 and 10.0.1.0/24.
+// IPv4 networks longer than /24 get the classless zone described in RFC 2317,
+// e.g. "0/25.2.0.192.in-addr.arpa." for 192.0.2.0/25.
+func ReverseZones(n *net.IPNet) []string {
+	ones, bits := n.Mask.Size()
+	if bits == 0 || bits != 8*len(n.IP) {
+		return nil
+	}
+	network := &net.IPNet{IP: NetworkAddr(n), Mask: n.Mask}
+
+	step := 4
+	if bits == 32 {
+		step = 8
+		if ones > 24 && ones < 32 {
+			return []string{fmt.Sprintf("%d/%d.%s", network.IP[3], ones, reverseZone(network.IP, 24))}
+		}
+	}
+
+	boundary := (ones + step - 1) / step * step
+	zones := []string{}
+	for _, sub := range splitNet(network, boundary) {
+		zones = append(zones, reverseZone(sub.IP, boundary))
+	}
+	return zones
+}
+
+// reverseZone returns the reverse DNS name for the first ones bits of the
+// given IP. For IPv4, ones must be a multiple of 8. For IPv6, it must be a
+// multiple of 4.
+func reverseZone(ip net.IP, ones int) string {
+	labels := []string{}
+	switch len(ip) {
+	case net.IPv4len:
+		for i := ones/8 - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(ip[i])))
+		}
+		labels = append(labels, ipv4ReverseSuffix)
+	case net.IPv6len:
+		for i := ones/4 - 1; i >= 0; i-- {
+			nibble := ip[i/2] & 0xf
+			if i%2 == 0 {
+				nibble = ip[i/2] >> 4
+			}
+			labels = append(labels, hexDigits[nibble:nibble+1])
+		}
+		labels = append(labels, ipv6ReverseSuffix)
+	default:
+		return ""
+	}
+	return strings.Join(labels, ".") + "."
+}
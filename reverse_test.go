@@ -0,0 +1,86 @@
+package netaddr
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseName(t *testing.T) {
+	assert.Equal(t, "4.3.2.1.in-addr.arpa.", ReverseName(ParseIP("1.2.3.4")))
+	assert.Equal(t, "0.0.0.0.in-addr.arpa.", ReverseName(ParseIP("0.0.0.0")))
+	assert.Equal(t, "b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa.", ReverseName(ParseIP("4321:0:1:2:3:4:567:89ab")))
+	assert.Equal(t, "", ReverseName(nil))
+	assert.Equal(t, "4.3.2.1.in-addr.arpa.", ReverseName(net.ParseIP("1.2.3.4")))
+	assert.Equal(t, "4.3.2.1.in-addr.arpa.", ReverseName(ParseIP("::ffff:1.2.3.4")))
+}
+
+func TestParseReverseName(t *testing.T) {
+	for _, in := range []string{"1.2.3.4", "255.0.10.0", "4321:0:1:2:3:4:567:89ab", "::", "2001:db8::1"} {
+		ip, err := ParseReverseName(ReverseName(ParseIP(in)))
+		assert.Nil(t, err, in)
+		assert.Equal(t, ParseIP(in), ip, in)
+	}
+
+	ip, err := ParseReverseName("4.3.2.1.IN-ADDR.ARPA")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("1.2.3.4"), ip)
+
+	ip, err = ParseReverseName("B.A.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("4321:0:1:2:3:4:567:89ab"), ip)
+
+	for _, tc := range []struct {
+		in  string
+		pos int
+	}{
+		{"", 0},
+		{"example.com.", 0},
+		{"in-addr.arpa.", 0},
+		{"2.0.192.in-addr.arpa.", 0},
+		{"1.4.3.2.1.in-addr.arpa.", 0},
+		{"4.3.256.1.in-addr.arpa.", 4},
+		{"4.3.02.1.in-addr.arpa.", 4},
+		{"4.3.+2.1.in-addr.arpa.", 4},
+		{"4.3..1.in-addr.arpa.", 4},
+		{"0.0.0.ip6.arpa.", 0},
+		{"b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.g.ip6.arpa.", 62},
+		{"b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.44.ip6.arpa.", 62},
+	} {
+		ip, err := ParseReverseName(tc.in)
+		assert.Nil(t, ip, tc.in)
+		if assert.IsType(t, &ParseError{}, err, tc.in) {
+			assert.Equal(t, tc.pos, err.(*ParseError).Pos, tc.in)
+			assert.True(t, errors.Is(err, ErrInvalidAddress))
+		}
+	}
+}
+
+func TestReverseZones(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		zones []string
+	}{
+		{"0.0.0.0/0", []string{"in-addr.arpa."}},
+		{"10.0.0.0/8", []string{"10.in-addr.arpa."}},
+		{"10.1.0.0/16", []string{"1.10.in-addr.arpa."}},
+		{"192.0.2.0/24", []string{"2.0.192.in-addr.arpa."}},
+		{"192.0.2.1/32", []string{"1.2.0.192.in-addr.arpa."}},
+		{"192.0.2.0/23", []string{"2.0.192.in-addr.arpa.", "3.0.192.in-addr.arpa."}},
+		{"10.0.0.0/14", []string{"0.10.in-addr.arpa.", "1.10.in-addr.arpa.", "2.10.in-addr.arpa.", "3.10.in-addr.arpa."}},
+		{"192.0.2.0/25", []string{"0/25.2.0.192.in-addr.arpa."}},
+		{"192.0.2.128/26", []string{"128/26.2.0.192.in-addr.arpa."}},
+		{"192.0.2.130/26", []string{"128/26.2.0.192.in-addr.arpa."}},
+		{"192.0.2.4/31", []string{"4/31.2.0.192.in-addr.arpa."}},
+		{"::/0", []string{"ip6.arpa."}},
+		{"2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa."}},
+		{"2001:db8::/31", []string{"8.b.d.0.1.0.0.2.ip6.arpa.", "9.b.d.0.1.0.0.2.ip6.arpa."}},
+		{"2001:db8::/30", []string{"8.b.d.0.1.0.0.2.ip6.arpa.", "9.b.d.0.1.0.0.2.ip6.arpa.", "a.b.d.0.1.0.0.2.ip6.arpa.", "b.b.d.0.1.0.0.2.ip6.arpa."}},
+	} {
+		assert.Equal(t, tc.zones, ReverseZones(parse(tc.in)), tc.in)
+	}
+	assert.Equal(t, 128, len(ReverseZones(parse("10.0.0.0/9"))))
+	assert.Equal(t, 8, len(ReverseZones(parse("2001:db8::/61"))))
+}
@@ -0,0 +1,143 @@
+package netaddr
+
+import (
+	"bytes"
+	"net"
+	"sort"
+)
+
+// CompareIPs returns -1, 0 or 1 when a is less than, equal to or greater than
+// b. IPv4 addresses come before IPv6 addresses and then addresses are ordered
+// numerically. IPv4 addresses in 16 byte form, like those from net.ParseIP,
+// are treated as IPv4 so 10.0.0.1 and ::ffff:10.0.0.1 are equal.
+func CompareIPs(a, b net.IP) int {
+	if a4 := a.To4(); a4 != nil {
+		a = a4
+	}
+	if b4 := b.To4(); b4 != nil {
+		b = b4
+	}
+	return compareIPBytes(a, b)
+}
+
+// compareIPBytes is like CompareIPs except that the IPs are compared as is, so
+// 4 byte IPs come before all 16 byte IPs. This is the order of IPLessThan,
+// which keeps 16 byte ranges contiguous for range arithmetic.
+func compareIPBytes(a, b net.IP) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
+
+// CompareNets returns -1, 0 or 1 when a is less than, equal to or greater
+// than b. Networks are ordered by IP like CompareIPs and then by prefix
+// length, shortest first.
+func CompareNets(a, b *net.IPNet) int {
+	if c := CompareIPs(a.IP, b.IP); c != 0 {
+		return c
+	}
+	aOnes, _ := a.Mask.Size()
+	bOnes, _ := b.Mask.Size()
+	if aOnes != bOnes {
+		if aOnes < bOnes {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// CompareRanges returns -1, 0 or 1 when a is less than, equal to or greater
+// than b. Ranges are ordered by First like CompareIPs and then by Last.
+func CompareRanges(a, b *IPRange) int {
+	if c := CompareIPs(a.First, b.First); c != 0 {
+		return c
+	}
+	return CompareIPs(a.Last, b.Last)
+}
+
+// SortIPs sorts the given IPs in place in the order of CompareIPs
+func SortIPs(ips []net.IP) {
+	sort.SliceStable(ips, func(i, j int) bool {
+		return CompareIPs(ips[i], ips[j]) < 0
+	})
+}
+
+// SortNets sorts the given networks in place in the order of CompareNets
+func SortNets(nets []*net.IPNet) {
+	sort.SliceStable(nets, func(i, j int) bool {
+		return CompareNets(nets[i], nets[j]) < 0
+	})
+}
+
+// SortRanges sorts the given ranges in place in the order of CompareRanges
+func SortRanges(ranges []*IPRange) {
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return CompareRanges(ranges[i], ranges[j]) < 0
+	})
+}
+
+// UniqueIPs returns a new, sorted slice of the given IPs with duplicates
+// removed. It does not modify the given slice. IPv4 addresses in 16 byte form
+// are returned as 4 byte addresses so that 10.0.0.1 and ::ffff:10.0.0.1, which
+// net.IP.Equal considers equal, are only kept once.
+func UniqueIPs(ips []net.IP) []net.IP {
+	sorted := make([]net.IP, len(ips))
+	for i, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		sorted[i] = ip
+	}
+	SortIPs(sorted)
+
+	unique := []net.IP{}
+	for _, ip := range sorted {
+		if len(unique) == 0 || CompareIPs(unique[len(unique)-1], ip) != 0 {
+			unique = append(unique, ip)
+		}
+	}
+	return unique
+}
+
+// UniqueNets returns a new, sorted slice of the given networks with duplicates
+// removed. It does not modify the given slice. Networks which overlap but are
+// not equal are all kept; use an IPSet to combine them. IPv4 networks with a
+// 16 byte IP or mask are returned in 4 byte form like UniqueIPs.
+func UniqueNets(nets []*net.IPNet) []*net.IPNet {
+	sorted := make([]*net.IPNet, len(nets))
+	for i, n := range nets {
+		sorted[i] = ipv4Net(n)
+	}
+	SortNets(sorted)
+
+	unique := []*net.IPNet{}
+	for _, n := range sorted {
+		if len(unique) == 0 || CompareNets(unique[len(unique)-1], n) != 0 {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
+
+// ipv4Net returns the given network with a 4 byte IP and mask if it is an IPv4
+// network in 16 byte form. Any other network is returned as is.
+func ipv4Net(n *net.IPNet) *net.IPNet {
+	ip4 := n.IP.To4()
+	if ip4 == nil || len(n.IP) == net.IPv4len {
+		return n
+	}
+	mask := n.Mask
+	if len(mask) == net.IPv6len {
+		if ones, _ := mask.Size(); ones < 96 {
+			// Reaches outside of the IPv4-mapped addresses
+			return n
+		}
+		mask = mask[12:]
+	}
+	return &net.IPNet{IP: ip4, Mask: mask}
+}
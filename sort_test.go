@@ -0,0 +1,109 @@
+package netaddr
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareIPs(t *testing.T) {
+	assert.Equal(t, 0, CompareIPs(ParseIP("10.0.0.1"), ParseIP("10.0.0.1")))
+	assert.Equal(t, -1, CompareIPs(ParseIP("10.0.0.1"), ParseIP("10.0.0.2")))
+	assert.Equal(t, 1, CompareIPs(ParseIP("10.0.1.0"), ParseIP("10.0.0.255")))
+	assert.Equal(t, -1, CompareIPs(ParseIP("255.255.255.255"), ParseIP("::")))
+	assert.Equal(t, 0, CompareIPs(net.ParseIP("10.0.0.1"), ParseIP("10.0.0.1")))
+	assert.Equal(t, -1, CompareIPs(net.ParseIP("10.0.0.1"), ParseIP("10.0.0.2")))
+	assert.Equal(t, -1, CompareIPs(ParseIP("::ffff:255.255.255.255"), ParseIP("::")))
+	assert.Equal(t, -1, CompareIPs(ParseIP("2001:db8::"), ParseIP("2001:db8::1")))
+}
+
+func TestCompareIPBytes(t *testing.T) {
+	assert.Equal(t, 0, compareIPBytes(ParseIP("10.0.0.1"), ParseIP("10.0.0.1")))
+	assert.Equal(t, -1, compareIPBytes(ParseIP("10.0.0.1"), net.ParseIP("10.0.0.1")))
+	assert.Equal(t, 1, compareIPBytes(ParseIP("::ffff:10.0.0.1"), ParseIP("::")))
+}
+
+func TestSortIPs(t *testing.T) {
+	ips := []net.IP{
+		ParseIP("2001:db8::1"),
+		ParseIP("10.0.0.2"),
+		ParseIP("::"),
+		ParseIP("192.168.0.1"),
+		ParseIP("10.0.0.1"),
+		ParseIP("::ffff:10.0.0.1"),
+	}
+	SortIPs(ips)
+	assert.Equal(t, "[10.0.0.1 10.0.0.1 10.0.0.2 192.168.0.1 :: 2001:db8::1]", fmt.Sprintf("%s", ips))
+
+	// IPv4 in 16 byte form sorts with the 4 byte form
+	ips = []net.IP{ParseIP("10.0.0.5"), net.ParseIP("10.0.0.1")}
+	SortIPs(ips)
+	assert.Equal(t, "[10.0.0.1 10.0.0.5]", fmt.Sprintf("%s", ips))
+}
+
+func TestSortNets(t *testing.T) {
+	nets := []*net.IPNet{
+		parse("2001:db8::/64"),
+		parse("10.0.0.0/24"),
+		parse("10.0.0.0/8"),
+		parse("2001:db8::/32"),
+		parse("10.0.0.0/16"),
+		parse("9.0.0.0/8"),
+	}
+	SortNets(nets)
+	assert.Equal(t, "[9.0.0.0/8 10.0.0.0/8 10.0.0.0/16 10.0.0.0/24 2001:db8::/32 2001:db8::/64]", fmt.Sprintf("%s", nets))
+}
+
+func TestSortRanges(t *testing.T) {
+	ranges := []*IPRange{
+		rng("2001:db8::1-2001:db8::ff"),
+		rng("10.0.0.1-10.0.0.50"),
+		rng("10.0.0.1-10.0.0.10"),
+		rng("9.0.0.1-10.0.0.0"),
+	}
+	SortRanges(ranges)
+	assert.Equal(t, "[[9.0.0.1,10.0.0.0] [10.0.0.1,10.0.0.10] [10.0.0.1,10.0.0.50] [2001:db8::1,2001:db8::ff]]", fmt.Sprintf("%s", ranges))
+}
+
+func TestUniqueIPs(t *testing.T) {
+	ips := []net.IP{
+		ParseIP("10.0.0.2"),
+		ParseIP("10.0.0.1"),
+		ParseIP("2001:db8::1"),
+		ParseIP("10.0.0.2"),
+		ParseIP("::ffff:10.0.0.1"),
+		ParseIP("2001:db8::1"),
+	}
+	unique := UniqueIPs(ips)
+	assert.Equal(t, "[10.0.0.1 10.0.0.2 2001:db8::1]", fmt.Sprintf("%s", unique))
+	assert.Equal(t, net.IPv4len, len(unique[0]))
+	assert.Equal(t, "10.0.0.2", ips[0].String())
+	assert.Equal(t, net.IPv6len, len(ips[4]))
+	assert.Equal(t, []net.IP{}, UniqueIPs(nil))
+}
+
+func TestUniqueNets(t *testing.T) {
+	nets := []*net.IPNet{
+		parse("10.0.0.0/24"),
+		parse("10.0.0.0/8"),
+		parse("10.0.0.0/24"),
+		parse("2001:db8::/32"),
+		parse("2001:db8::/32"),
+	}
+	assert.Equal(t, "[10.0.0.0/8 10.0.0.0/24 2001:db8::/32]", fmt.Sprintf("%s", UniqueNets(nets)))
+	assert.Equal(t, "10.0.0.0/24", nets[0].String())
+
+	// IPv4 networks in 16 byte form are the same as 4 byte networks
+	long := &net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(24, 32)}
+	mapped := &net.IPNet{IP: net.ParseIP("::ffff:10.0.0.0"), Mask: net.CIDRMask(120, 128)}
+	unique := UniqueNets([]*net.IPNet{parse("10.0.0.0/24"), long, mapped})
+	assert.Equal(t, []*net.IPNet{parse("10.0.0.0/24")}, unique)
+	assert.Equal(t, net.IPv6len, len(long.IP))
+
+	// Networks reaching outside of the IPv4-mapped addresses are IPv6
+	wide := &net.IPNet{IP: net.ParseIP("::ffff:0.0.0.0"), Mask: net.CIDRMask(80, 128)}
+	assert.Equal(t, []*net.IPNet{wide}, UniqueNets([]*net.IPNet{wide}))
+	assert.Equal(t, []*net.IPNet{}, UniqueNets(nil))
+}
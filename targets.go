@@ -0,0 +1,220 @@
+package netaddr
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseTargets parses a target specification in the grammar used by nmap and
+// returns the IPs it names. Targets are separated by whitespace or commas and
+// may be any of the following:
+//
+//	192.168.1.1           a single IPv4 address
+//	192.168.1.0/24        a CIDR; the host part may be non-zero
+//	192.168.1-3.1-254     octet ranges
+//	10.0.3,5,7-9.*        octet lists; * is the same as 0-255
+//	10.0.0.-100           open ended ranges; -100 is 0-100 and 100- is 100-255
+//	2001:db8::1           a single IPv6 address
+//	2001:db8::/64         an IPv6 CIDR
+//
+// Any excludes are parsed the same way and their IPs are removed from the
+// result like nmap's --exclude option. Errors are returned as *ParseError with
+// the position of the target that failed.
+func ParseTargets(spec string, excludes ...string) (*IPSet, error) {
+	set := &IPSet{}
+	pieces, offsets := splitOffsets(spec, " \t\r\n")
+	for i, piece := range pieces {
+		if err := insertTarget(set, piece); err != nil {
+			return nil, rebaseError(err, spec, offsets[i])
+		}
+	}
+
+	for _, exclude := range excludes {
+		excluded, err := ParseTargets(exclude)
+		if err != nil {
+			return nil, err
+		}
+		set = set.Difference(excluded)
+	}
+	return set, nil
+}
+
+// insertTarget inserts the IPs named by a single whitespace delimited target.
+// Commas in IPv4 targets separate octet values, as in 10.0.3,5.1, unless the
+// target has the wrong number of octets or begins or ends with a comma, in
+// which case it is a list of targets instead.
+func insertTarget(set *IPSet, target string) error {
+	if strings.Contains(target, ":") || strings.Contains(target, "/") {
+		if !strings.Contains(target, ",") {
+			return insertAddressOrCIDR(set, target)
+		}
+	} else {
+		list := strings.Count(target, ".") != net.IPv4len-1 ||
+			strings.HasPrefix(target, ",") || strings.HasSuffix(target, ",")
+		if !list || !strings.Contains(target, ",") {
+			octets, err := parseOctetRanges(target, true)
+			if err != nil {
+				return err
+			}
+			insertOctetRanges(set, octets)
+			return nil
+		}
+	}
+
+	pieces, offsets := splitOffsets(target, ",")
+	for i, piece := range pieces {
+		if strings.Contains(piece, ":") || strings.Contains(piece, "/") {
+			if err := insertAddressOrCIDR(set, piece); err != nil {
+				return rebaseError(err, target, offsets[i])
+			}
+			continue
+		}
+		octets, err := parseOctetRanges(piece, true)
+		if err != nil {
+			return rebaseError(err, target, offsets[i])
+		}
+		insertOctetRanges(set, octets)
+	}
+	return nil
+}
+
+// insertAddressOrCIDR inserts a single IP or all of the IPs in a CIDR
+func insertAddressOrCIDR(set *IPSet, str string) error {
+	if strings.Contains(str, "/") {
+		_, n, err := ParseCIDR(str)
+		if err != nil {
+			return err
+		}
+		set.InsertNet(n)
+		return nil
+	}
+	ip, err := ParseIPErr(str)
+	if err != nil {
+		return err
+	}
+	set.Insert(ip)
+	return nil
+}
+
+// octetRange is an inclusive range of values for one octet of an address
+type octetRange struct {
+	first, last byte
+}
+
+// parseOctetRanges parses an IPv4 address where each octet is a comma
+// separated list of values or ranges. Ranges may only leave out an end, as in
+// -100 or 100-, when openEnded is true.
+func parseOctetRanges(str string, openEnded bool) ([][]octetRange, error) {
+	parts := strings.Split(str, ".")
+	if len(parts) != net.IPv4len {
+		return nil, &ParseError{Input: str, Pos: 0, Err: fmt.Errorf("expected %d octets: %w", net.IPv4len, ErrInvalidAddress)}
+	}
+
+	octets := make([][]octetRange, len(parts))
+	start := 0
+	for i, part := range parts {
+		items, offsets := splitOffsets(part, ",")
+		if len(items) == 0 || strings.HasPrefix(part, ",") || strings.HasSuffix(part, ",") || strings.Contains(part, ",,") {
+			return nil, &ParseError{Input: str, Pos: start, Err: ErrInvalidAddress}
+		}
+		for j, item := range items {
+			r, ok := parseOctetRange(item, openEnded)
+			if !ok {
+				return nil, &ParseError{Input: str, Pos: start + offsets[j], Err: ErrInvalidAddress}
+			}
+			octets[i] = append(octets[i], r)
+		}
+		start += len(part) + 1
+	}
+	return octets, nil
+}
+
+// parseOctetRange parses one octet value, a range like 1-254 or, if openEnded
+// is true, -100 or 100-, or * for any value
+func parseOctetRange(str string, openEnded bool) (octetRange, bool) {
+	if str == "*" {
+		return octetRange{0, 255}, true
+	}
+	parseOctet := func(str string, missing byte) (byte, bool) {
+		if str == "" {
+			return missing, true
+		}
+		if str[0] == '+' {
+			return 0, false
+		}
+		value, err := strconv.ParseUint(str, 10, 8)
+		return byte(value), err == nil
+	}
+
+	dash := strings.Index(str, "-")
+	if dash < 0 {
+		value, ok := parseOctet(str, 0)
+		return octetRange{value, value}, ok && str != ""
+	}
+	if !openEnded && (dash == 0 || dash == len(str)-1) {
+		return octetRange{}, false
+	}
+	first, ok1 := parseOctet(str[:dash], 0)
+	last, ok2 := parseOctet(str[dash+1:], 255)
+	return octetRange{first, last}, ok1 && ok2 && first <= last
+}
+
+// insertOctetRanges inserts every address matching the octet ranges
+func insertOctetRanges(set *IPSet, octets [][]octetRange) {
+	// Trailing octets which match any value become part of a larger range
+	// instead of being enumerated
+	k := len(octets) - 1
+	for k > 0 && len(octets[k]) == 1 && octets[k][0] == (octetRange{0, 255}) {
+		k--
+	}
+
+	var visit func(prefix net.IP, i int)
+	visit = func(prefix net.IP, i int) {
+		if i < k {
+			for _, r := range octets[i] {
+				for v := int(r.first); v <= int(r.last); v++ {
+					prefix[i] = byte(v)
+					visit(prefix, i+1)
+				}
+			}
+			return
+		}
+		for _, r := range octets[k] {
+			first, last := NewIP(net.IPv4len), NewIP(net.IPv4len)
+			copy(first, prefix[:k])
+			copy(last, prefix[:k])
+			first[k], last[k] = r.first, r.last
+			for j := k + 1; j < net.IPv4len; j++ {
+				last[j] = 255
+			}
+			for _, n := range rangeToNets(first, last) {
+				set.InsertNet(n)
+			}
+		}
+	}
+	visit(NewIP(net.IPv4len), 0)
+}
+
+// splitOffsets splits str at any of the separator characters, dropping empty
+// pieces, and returns the pieces along with their offsets in str
+func splitOffsets(str, seps string) (pieces []string, offsets []int) {
+	start := -1
+	for i, c := range str {
+		if strings.ContainsRune(seps, c) {
+			if start >= 0 {
+				pieces = append(pieces, str[start:i])
+				offsets = append(offsets, start)
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		pieces = append(pieces, str[start:])
+		offsets = append(offsets, start)
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package netaddr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTargets(t *testing.T) {
+	for _, tc := range []struct {
+		spec   string
+		result string
+	}{
+		{"", "[]"},
+		{"192.168.1.1", "[192.168.1.1/32]"},
+		{"192.168.1.1/24", "[192.168.1.0/24]"},
+		{"192.168.1-3.1-254", "[192.168.1.1/32 192.168.1.2/31 192.168.1.4/30 192.168.1.8/29 192.168.1.16/28 192.168.1.32/27 192.168.1.64/26 192.168.1.128/26 192.168.1.192/27 192.168.1.224/28 192.168.1.240/29 192.168.1.248/30 192.168.1.252/31 192.168.1.254/32 192.168.2.1/32 192.168.2.2/31 192.168.2.4/30 192.168.2.8/29 192.168.2.16/28 192.168.2.32/27 192.168.2.64/26 192.168.2.128/26 192.168.2.192/27 192.168.2.224/28 192.168.2.240/29 192.168.2.248/30 192.168.2.252/31 192.168.2.254/32 192.168.3.1/32 192.168.3.2/31 192.168.3.4/30 192.168.3.8/29 192.168.3.16/28 192.168.3.32/27 192.168.3.64/26 192.168.3.128/26 192.168.3.192/27 192.168.3.224/28 192.168.3.240/29 192.168.3.248/30 192.168.3.252/31 192.168.3.254/32]"},
+		{"10.0.3,5,7-8.1", "[10.0.3.1/32 10.0.5.1/32 10.0.7.1/32 10.0.8.1/32]"},
+		{"10.0.0-1.*", "[10.0.0.0/23]"},
+		{"10.*.*.*", "[10.0.0.0/8]"},
+		{"*.*.*.*", "[0.0.0.0/0]"},
+		{"10.0.0.-3", "[10.0.0.0/30]"},
+		{"10.0.0.252-", "[10.0.0.252/30]"},
+		{"10.0.0.1,10.0.0.2", "[10.0.0.1/32 10.0.0.2/32]"},
+		{"10.0.0.1, 10.0.0.2\n10.0.0.3", "[10.0.0.1/32 10.0.0.2/31]"},
+		{"10.0.0.1 2001:db8::1", "[10.0.0.1/32 2001:db8::1/128]"},
+		{"2001:db8::1/126,10.0.0.0/30", "[10.0.0.0/30 2001:db8::/126]"},
+	} {
+		set, err := ParseTargets(tc.spec)
+		if assert.Nil(t, err, tc.spec) {
+			assert.Equal(t, tc.result, set.String(), tc.spec)
+			assert.Equal(t, []error{}, set.tree.validate())
+		}
+	}
+}
+
+func TestParseTargetsExclude(t *testing.T) {
+	set, err := ParseTargets("192.168.1.0/24", "192.168.1.0/25", "192.168.1.128,255")
+	assert.Nil(t, err)
+	assert.Equal(t, "[192.168.1.129/32 192.168.1.130/31 192.168.1.132/30 192.168.1.136/29 192.168.1.144/28 192.168.1.160/27 192.168.1.192/27 192.168.1.224/28 192.168.1.240/29 192.168.1.248/30 192.168.1.252/31 192.168.1.254/32]", set.String())
+
+	set, err = ParseTargets("10.0.0.0/24", "10.0.0.1 bogus")
+	assert.Nil(t, set)
+	if assert.IsType(t, &ParseError{}, err) {
+		assert.Equal(t, "10.0.0.1 bogus", err.(*ParseError).Input)
+		assert.Equal(t, 9, err.(*ParseError).Pos)
+		assert.True(t, errors.Is(err, ErrInvalidAddress))
+	}
+}
+
+func TestParseTargetsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		pos  int
+	}{
+		{"bogus", 0},
+		{"10.0.0", 0},
+		{"10.0.0.256", 7},
+		{"10.0.0.5-1", 7},
+		{"10.0.0.1-2-3", 7},
+		{"10.0.0.+1", 7},
+		{"10.0.1,,2.1", 5},
+		{"10.0.1,2,.1", 5},
+		{"10.0..1", 5},
+		{"10.0.0.1 10.0.0.x", 16},
+		{"10.0.0.1,10.0.0.x", 16},
+		{"10.0.0.1,2001:db8::g", 19},
+		{"10.0.0.0/33", 10},
+		{"10.0.0.1 2001:db8::/129", 22},
+	} {
+		set, err := ParseTargets(tc.spec)
+		assert.Nil(t, set, tc.spec)
+		if assert.IsType(t, &ParseError{}, err, tc.spec) {
+			assert.Equal(t, tc.spec, err.(*ParseError).Input)
+			assert.Equal(t, tc.pos, err.(*ParseError).Pos, tc.spec)
+		}
+	}
+}
+
+func TestSplitOffsets(t *testing.T) {
+	pieces, offsets := splitOffsets(" a,b  c,", " ,")
+	assert.Equal(t, []string{"a", "b", "c"}, pieces)
+	assert.Equal(t, []int{1, 3, 6}, offsets)
+
+	pieces, offsets = splitOffsets("", " ")
+	assert.Nil(t, pieces)
+	assert.Nil(t, offsets)
+}
@@ -0,0 +1,162 @@
+package netaddr
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+)
+
+// WildcardNet is an IP address with a wildcard mask as found in Cisco and
+// Juniper ACLs, e.g. "10.0.0.0 0.0.255.255". Bits which are one in the
+// wildcard match any value. Unlike a netmask, the ones in a wildcard do not
+// need to be contiguous so "10.0.0.1 0.0.255.0" matches 10.0.X.1 for any X.
+type WildcardNet struct {
+	IP       net.IP
+	Wildcard net.IPMask
+}
+
+// NewWildcardNet returns a new WildcardNet. The bits of ip that the wildcard
+// ignores are cleared. It returns nil if ip and wildcard are not the same
+// size.
+func NewWildcardNet(ip net.IP, wildcard net.IPMask) *WildcardNet {
+	if len(ip) != len(wildcard) {
+		return nil
+	}
+	w := &WildcardNet{IP: make(net.IP, len(ip)), Wildcard: wildcard}
+	for i := range ip {
+		w.IP[i] = ip[i] &^ wildcard[i]
+	}
+	return w
+}
+
+// ParseWildcardNet parses an address and wildcard mask separated by
+// whitespace, e.g. "10.0.0.0 0.0.255.255". It also accepts the ACL keywords
+// "any", for all IPv4 addresses, and "host 10.0.0.1" for a single address.
+// Errors are returned as *ParseError.
+func ParseWildcardNet(str string) (*WildcardNet, error) {
+	fields := strings.Fields(str)
+	if len(fields) == 1 && fields[0] == "any" {
+		return NewWildcardNet(NewIP(net.IPv4len), net.CIDRMask(32, 32)), nil
+	}
+	if len(fields) != 2 {
+		return nil, &ParseError{Input: str, Pos: 0, Err: fmt.Errorf("expected an address and wildcard: %w", ErrInvalidAddress)}
+	}
+	ipPos := strings.Index(str, fields[0])
+	maskPos := ipPos + len(fields[0]) + strings.Index(str[ipPos+len(fields[0]):], fields[1])
+
+	if fields[0] == "host" {
+		ip := ParseIP(fields[1])
+		if ip == nil {
+			return nil, &ParseError{Input: str, Pos: maskPos + invalidAddressPos(fields[1]), Err: ErrInvalidAddress}
+		}
+		return NewWildcardNet(ip, make(net.IPMask, len(ip))), nil
+	}
+
+	ip := ParseIP(fields[0])
+	if ip == nil {
+		return nil, &ParseError{Input: str, Pos: ipPos + invalidAddressPos(fields[0]), Err: ErrInvalidAddress}
+	}
+	wildcard := ParseIP(fields[1])
+	if wildcard == nil {
+		return nil, &ParseError{Input: str, Pos: maskPos + invalidAddressPos(fields[1]), Err: ErrInvalidAddress}
+	}
+	if len(ip) != len(wildcard) {
+		return nil, &ParseError{Input: str, Pos: maskPos, Err: ErrFamilyMismatch}
+	}
+	return NewWildcardNet(ip, net.IPMask(wildcard)), nil
+}
+
+// WildcardNetFromIPNet returns the WildcardNet matching the same IPs as the
+// given network
+func WildcardNetFromIPNet(n *net.IPNet) *WildcardNet {
+	wildcard := make(net.IPMask, len(n.Mask))
+	for i := range n.Mask {
+		wildcard[i] = ^n.Mask[i]
+	}
+	return NewWildcardNet(n.IP, wildcard)
+}
+
+// String returns the address and wildcard separated by a space, e.g.
+// "10.0.0.0 0.0.255.255"
+func (w *WildcardNet) String() string {
+	return w.IP.String() + " " + net.IP(w.Wildcard).String()
+}
+
+// Contains returns true if the given IP matches the address in all of the bits
+// which are zero in the wildcard
+func (w *WildcardNet) Contains(ip net.IP) bool {
+	if len(ip) != len(w.IP) {
+		return false
+	}
+	for i := range ip {
+		if (ip[i]^w.IP[i])&^w.Wildcard[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns the number of IPs matched
+func (w *WildcardNet) Size() *big.Int {
+	scattered, trailing := w.splitWildcard()
+	return big.NewInt(0).Lsh(big.NewInt(1), uint(len(scattered)+trailing))
+}
+
+// IPNet returns the network matching the same IPs or nil if the wildcard is
+// not contiguous
+func (w *WildcardNet) IPNet() *net.IPNet {
+	scattered, trailing := w.splitWildcard()
+	if len(scattered) != 0 {
+		return nil
+	}
+	bits := 8 * len(w.IP)
+	return &net.IPNet{IP: w.IP, Mask: net.CIDRMask(bits-trailing, bits)}
+}
+
+// ToIPSet returns the IPs matched as an IPSet. Each one bit in the wildcard
+// outside of the trailing run of ones doubles the number of CIDRs needed. If
+// more than limit CIDRs would be needed, it returns an error wrapping
+// ErrTooManyNetworks instead.
+func (w *WildcardNet) ToIPSet(limit int) (*IPSet, error) {
+	scattered, trailing := w.splitWildcard()
+	if len(scattered) >= 31 || 1<<uint(len(scattered)) > limit {
+		return nil, fmt.Errorf("wildcard %s needs 2^%d networks: %w", w, len(scattered), ErrTooManyNetworks)
+	}
+
+	bits := 8 * len(w.IP)
+	mask := net.CIDRMask(bits-trailing, bits)
+	set := &IPSet{}
+	for i := 0; i < 1<<uint(len(scattered)); i++ {
+		ip := make(net.IP, len(w.IP))
+		copy(ip, w.IP)
+		for j, b := range scattered {
+			if i&(1<<uint(j)) != 0 {
+				ip[b/8] |= 0x80 >> uint(b%8)
+			}
+		}
+		set.InsertNet(&net.IPNet{IP: ip, Mask: mask})
+	}
+	return set, nil
+}
+
+// splitWildcard returns the positions, counting from the most significant bit,
+// of the ones in the wildcard which are not part of the trailing run of ones
+// and the length of that trailing run.
+func (w *WildcardNet) splitWildcard() (scattered []int, trailing int) {
+	bits := 8 * len(w.Wildcard)
+	isSet := func(b int) bool {
+		return w.Wildcard[b/8]&(0x80>>uint(b%8)) != 0
+	}
+
+	b := bits - 1
+	for ; b >= 0 && isSet(b); b-- {
+		trailing++
+	}
+	for ; b >= 0; b-- {
+		if isSet(b) {
+			scattered = append(scattered, b)
+		}
+	}
+	return
+}
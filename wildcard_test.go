@@ -0,0 +1,112 @@
+package netaddr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWildcardNet(t *testing.T) {
+	for _, tc := range []struct {
+		in, result string
+	}{
+		{"10.0.0.0 0.0.255.255", "10.0.0.0 0.0.255.255"},
+		{"  10.0.0.0\t0.0.255.255 ", "10.0.0.0 0.0.255.255"},
+		{"10.0.0.1 0.0.255.0", "10.0.0.1 0.0.255.0"},
+		{"10.0.7.1 0.0.255.0", "10.0.0.1 0.0.255.0"},
+		{"any", "0.0.0.0 255.255.255.255"},
+		{"host 10.0.0.1", "10.0.0.1 0.0.0.0"},
+		{"host 2001:db8::1", "2001:db8::1 ::"},
+		{"2001:db8:: ::ffff", "2001:db8:: ::ffff"},
+	} {
+		w, err := ParseWildcardNet(tc.in)
+		if assert.Nil(t, err, tc.in) {
+			assert.Equal(t, tc.result, w.String())
+		}
+	}
+}
+
+func TestParseWildcardNetErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+		err error
+	}{
+		{"", 0, ErrInvalidAddress},
+		{"10.0.0.0", 0, ErrInvalidAddress},
+		{"10.0.0.0 0.0.0.255 extra", 0, ErrInvalidAddress},
+		{"10.0.0.x 0.0.0.255", 7, ErrInvalidAddress},
+		{"10.0.0.0 0.0.0.256", 15, ErrInvalidAddress},
+		{"host 10.0.0.256", 12, ErrInvalidAddress},
+		{"10.0.0.0 ::ff", 9, ErrFamilyMismatch},
+	} {
+		w, err := ParseWildcardNet(tc.in)
+		assert.Nil(t, w)
+		if assert.IsType(t, &ParseError{}, err, tc.in) {
+			assert.Equal(t, tc.pos, err.(*ParseError).Pos, tc.in)
+			assert.True(t, errors.Is(err, tc.err), tc.in)
+		}
+	}
+}
+
+func TestWildcardNetContains(t *testing.T) {
+	w, _ := ParseWildcardNet("10.0.0.1 0.0.255.0")
+	assert.True(t, w.Contains(ParseIP("10.0.0.1")))
+	assert.True(t, w.Contains(ParseIP("10.0.77.1")))
+	assert.True(t, w.Contains(ParseIP("10.0.255.1")))
+	assert.False(t, w.Contains(ParseIP("10.0.0.2")))
+	assert.False(t, w.Contains(ParseIP("10.1.0.1")))
+	assert.False(t, w.Contains(ParseIP("::ffff:10.0.0.1")))
+
+	w, _ = ParseWildcardNet("any")
+	assert.True(t, w.Contains(ParseIP("192.0.2.1")))
+	assert.False(t, w.Contains(ParseIP("2001:db8::1")))
+}
+
+func TestWildcardNetIPNet(t *testing.T) {
+	w, _ := ParseWildcardNet("10.0.0.0 0.0.255.255")
+	assert.Equal(t, parse("10.0.0.0/16"), w.IPNet())
+	assert.Equal(t, "65536", w.Size().String())
+	assert.Equal(t, w, WildcardNetFromIPNet(parse("10.0.0.0/16")))
+
+	w, _ = ParseWildcardNet("host 10.0.0.1")
+	assert.Equal(t, parse("10.0.0.1/32"), w.IPNet())
+	assert.Equal(t, "1", w.Size().String())
+
+	w, _ = ParseWildcardNet("10.0.0.1 0.0.255.0")
+	assert.Nil(t, w.IPNet())
+	assert.Equal(t, "256", w.Size().String())
+
+	assert.Nil(t, NewWildcardNet(ParseIP("10.0.0.1"), make([]byte, 16)))
+}
+
+func TestWildcardNetToIPSet(t *testing.T) {
+	w, _ := ParseWildcardNet("10.0.0.0 0.0.255.255")
+	set, err := w.ToIPSet(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "[10.0.0.0/16]", set.String())
+
+	w, _ = ParseWildcardNet("10.0.0.0 0.0.1.3")
+	set, err = w.ToIPSet(2)
+	assert.Nil(t, err)
+	assert.Equal(t, "[10.0.0.0/30 10.0.1.0/30]", set.String())
+
+	w, _ = ParseWildcardNet("10.0.0.1 0.0.255.0")
+	set, err = w.ToIPSet(256)
+	assert.Nil(t, err)
+	assert.Equal(t, 256, len(set.GetNetworks()))
+	assert.True(t, set.Contains(ParseIP("10.0.77.1")))
+	assert.False(t, set.Contains(ParseIP("10.0.77.2")))
+	assert.Equal(t, w.Size(), set.tree.size())
+	assert.Equal(t, []error{}, set.tree.validate())
+
+	set, err = w.ToIPSet(255)
+	assert.Nil(t, set)
+	assert.True(t, errors.Is(err, ErrTooManyNetworks))
+
+	w, _ = ParseWildcardNet("2001:db8:: ffff:ffff:ffff:ffff::")
+	set, err = w.ToIPSet(1 << 20)
+	assert.Nil(t, set)
+	assert.True(t, errors.Is(err, ErrTooManyNetworks))
+}
@@ -0,0 +1,134 @@
+package netaddr
+
+import (
+	"net"
+	"strings"
+)
+
+// ParseIPZone is like ParseIPErr except that it also accepts an IPv6 address
+// with a zone, e.g. "fe80::1%eth0", and returns the zone separately. The zone
+// is empty if there is none. An empty zone or a zone on an IPv4 address
+// results in ErrInvalidZone.
+func ParseIPZone(address string) (net.IP, string, error) {
+	percent := strings.Index(address, "%")
+	if percent < 0 {
+		ip, err := ParseIPErr(address)
+		return ip, "", err
+	}
+
+	ip := ParseIP(address[:percent])
+	if ip == nil {
+		return nil, "", &ParseError{Input: address, Pos: invalidAddressPos(address[:percent]), Err: ErrInvalidAddress}
+	}
+	zone := address[percent+1:]
+	if len(ip) != net.IPv6len || zone == "" {
+		return nil, "", &ParseError{Input: address, Pos: percent, Err: ErrInvalidZone}
+	}
+	return ip, zone, nil
+}
+
+// ParseCIDRZone is like ParseCIDR except that it also accepts a zone after the
+// IPv6 address, e.g. "fe80::1%eth0/64", and returns the zone separately
+func ParseCIDRZone(cidr string) (net.IP, *net.IPNet, string, error) {
+	percent := strings.Index(cidr, "%")
+	if percent < 0 {
+		ip, ipNet, err := ParseCIDR(cidr)
+		return ip, ipNet, "", err
+	}
+
+	if strings.Contains(cidr[:percent], "/") {
+		// The zone belongs to the address, not after the prefix length
+		return net.IP{}, nil, "", &ParseError{Input: cidr, Pos: percent, Err: ErrInvalidZone}
+	}
+	slash := strings.LastIndex(cidr, "/")
+	if slash < percent {
+		slash = len(cidr)
+	}
+	zone := cidr[percent+1 : slash]
+
+	// Parse it without the zone and fix up the position of any error
+	ip, ipNet, err := ParseCIDR(cidr[:percent] + cidr[slash:])
+	if err != nil {
+		perr := err.(*ParseError)
+		if perr.Pos >= percent {
+			perr.Pos += len(zone) + 1
+		}
+		perr.Input = cidr
+		return net.IP{}, nil, "", perr
+	}
+	if len(ip) != net.IPv6len || zone == "" {
+		return net.IP{}, nil, "", &ParseError{Input: cidr, Pos: percent, Err: ErrInvalidZone}
+	}
+	return ip, ipNet, zone, nil
+}
+
+// FormatIPZone returns the given IP with the zone appended after a "%" if it
+// isn't empty. ParseIPZone accepts the result.
+func FormatIPZone(ip net.IP, zone string) string {
+	if zone == "" {
+		return ip.String()
+	}
+	return ip.String() + "%" + zone
+}
+
+// ZonedIPSet is a set of IP addresses where each address belongs to a zone,
+// such as the interface of a link-local address. The same address in two
+// zones is two different members of the set. Addresses without a zone belong
+// to the empty zone.
+type ZonedIPSet struct {
+	// zones keeps the IPs of each zone as a source of an IPMultiSet
+	zones IPMultiSet
+}
+
+// multi returns the IPMultiSet which holds the zones, or nil for a nil set
+func (z *ZonedIPSet) multi() *IPMultiSet {
+	if z == nil {
+		return nil
+	}
+	return &z.zones
+}
+
+// InsertNet ensures this set has the entire given IP network in the given zone
+func (z *ZonedIPSet) InsertNet(zone string, net *net.IPNet) {
+	z.multi().InsertNet(zone, net)
+}
+
+// Insert ensures this set has the given IP in the given zone
+func (z *ZonedIPSet) Insert(zone string, ip net.IP) {
+	z.InsertNet(zone, ipToNet(ip))
+}
+
+// RemoveNet ensures that none of the IPs in the given network are in the
+// given zone. Other zones are not affected.
+func (z *ZonedIPSet) RemoveNet(zone string, net *net.IPNet) {
+	z.multi().RemoveNet(zone, net)
+}
+
+// Remove ensures that the given IP is not in the given zone
+func (z *ZonedIPSet) Remove(zone string, ip net.IP) {
+	z.RemoveNet(zone, ipToNet(ip))
+}
+
+// ContainsNet returns true iff the given zone contains all IPs in the given
+// network
+func (z *ZonedIPSet) ContainsNet(zone string, net *net.IPNet) bool {
+	if z == nil {
+		return false
+	}
+	return z.zones.sources[zone].ContainsNet(net)
+}
+
+// Contains returns true iff the given zone contains the given IP
+func (z *ZonedIPSet) Contains(zone string, ip net.IP) bool {
+	return z.ContainsNet(zone, ipToNet(ip))
+}
+
+// Zones returns the sorted names of the zones with at least one IP
+func (z *ZonedIPSet) Zones() []string {
+	return z.multi().SourceIDs()
+}
+
+// Zone returns a copy of the IPs in the given zone
+func (z *ZonedIPSet) Zone(zone string) *IPSet {
+	return z.multi().Source(zone)
+}
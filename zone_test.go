@@ -0,0 +1,136 @@
+package netaddr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPZone(t *testing.T) {
+	for _, tc := range []struct {
+		in, ip, zone string
+	}{
+		{"fe80::1%eth0", "fe80::1", "eth0"},
+		{"fe80::1%25", "fe80::1", "25"},
+		{"fe80::1%en0%x", "fe80::1", "en0%x"},
+		{"fe80::1", "fe80::1", ""},
+		{"10.0.0.1", "10.0.0.1", ""},
+	} {
+		ip, zone, err := ParseIPZone(tc.in)
+		if assert.Nil(t, err, tc.in) {
+			assert.Equal(t, ParseIP(tc.ip), ip)
+			assert.Equal(t, tc.zone, zone)
+			assert.Equal(t, tc.in, FormatIPZone(ip, zone))
+		}
+	}
+
+	for _, tc := range []struct {
+		in  string
+		pos int
+		err error
+	}{
+		{"fe80::1%", 7, ErrInvalidZone},
+		{"10.0.0.1%eth0", 8, ErrInvalidZone},
+		{"fe80::g%eth0", 6, ErrInvalidAddress},
+		{"bogus", 0, ErrInvalidAddress},
+	} {
+		ip, zone, err := ParseIPZone(tc.in)
+		assert.Nil(t, ip)
+		assert.Equal(t, "", zone)
+		assert.Equal(t, &ParseError{Input: tc.in, Pos: tc.pos, Err: tc.err}, err, tc.in)
+	}
+}
+
+func TestParseCIDRZone(t *testing.T) {
+	ip, n, zone, err := ParseCIDRZone("fe80::1%eth0/64")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("fe80::1"), ip)
+	assert.Equal(t, parse("fe80::/64"), n)
+	assert.Equal(t, "eth0", zone)
+
+	ip, n, zone, err = ParseCIDRZone("10.0.0.1/24")
+	assert.Nil(t, err)
+	assert.Equal(t, ParseIP("10.0.0.1"), ip)
+	assert.Equal(t, parse("10.0.0.0/24"), n)
+	assert.Equal(t, "", zone)
+
+	for _, tc := range []struct {
+		in  string
+		pos int
+		err error
+	}{
+		{"fe80::1%eth0", 12, ErrInvalidPrefixLength},
+		{"fe80::1%eth0/129", 15, ErrInvalidPrefixLength},
+		{"fe80::1%/64", 7, ErrInvalidZone},
+		{"10.0.0.1%eth0/24", 8, ErrInvalidZone},
+		{"fe80::g%eth0/64", 6, ErrInvalidAddress},
+		{"fe80::/64%eth0", 9, ErrInvalidZone},
+		{"fe80::1/64%", 10, ErrInvalidZone},
+	} {
+		ip, n, zone, err := ParseCIDRZone(tc.in)
+		assert.Equal(t, 0, len(ip))
+		assert.Nil(t, n)
+		assert.Equal(t, "", zone)
+		assert.Equal(t, &ParseError{Input: tc.in, Pos: tc.pos, Err: tc.err}, err, tc.in)
+	}
+}
+
+func TestZonedIPSet(t *testing.T) {
+	z := ZonedIPSet{}
+	linkLocal := ParseIP("fe80::1")
+
+	assert.False(t, z.Contains("eth0", linkLocal))
+	assert.Equal(t, []string{}, z.Zones())
+
+	z.Insert("eth0", linkLocal)
+	z.InsertNet("eth1", parse("fe80::/64"))
+	z.Insert("", linkLocal)
+	assert.True(t, z.Contains("eth0", linkLocal))
+	assert.True(t, z.Contains("eth1", linkLocal))
+	assert.True(t, z.Contains("", linkLocal))
+	assert.False(t, z.Contains("eth2", linkLocal))
+	assert.False(t, z.Contains("eth0", ParseIP("fe80::2")))
+	assert.True(t, z.Contains("eth1", ParseIP("fe80::2")))
+	assert.True(t, z.ContainsNet("eth1", parse("fe80::/64")))
+	assert.False(t, z.ContainsNet("eth0", parse("fe80::/64")))
+	assert.Equal(t, []string{"", "eth0", "eth1"}, z.Zones())
+
+	// Removing from one zone doesn't touch the others
+	z.Remove("eth1", linkLocal)
+	assert.False(t, z.Contains("eth1", linkLocal))
+	assert.True(t, z.Contains("eth0", linkLocal))
+
+	z.Remove("eth0", linkLocal)
+	assert.Equal(t, []string{"", "eth1"}, z.Zones())
+
+	s := z.Zone("eth1")
+	assert.True(t, s.Contains(ParseIP("fe80::2")))
+	s.Remove(ParseIP("fe80::2"))
+	assert.True(t, z.Contains("eth1", ParseIP("fe80::2")))
+	assert.Equal(t, "[]", z.Zone("bogus").String())
+
+	var nilSet *ZonedIPSet
+	assert.False(t, nilSet.Contains("eth0", linkLocal))
+	assert.Equal(t, []string{}, nilSet.Zones())
+	nilSet.Remove("eth0", linkLocal)
+	assert.Equal(t, []string(nil), nilSet.Zone("eth0").Strings())
+}
+
+func TestZonedIPSetRoundTrip(t *testing.T) {
+	z := ZonedIPSet{}
+	for _, in := range []string{"fe80::1%eth0", "fe80::1%eth1", "fe80::2%eth0"} {
+		ip, zone, err := ParseIPZone(in)
+		assert.Nil(t, err)
+		z.Insert(zone, ip)
+	}
+
+	out := []string{}
+	for _, zone := range z.Zones() {
+		for _, ip := range z.Zone(zone).GetIPs(0) {
+			out = append(out, FormatIPZone(ip, zone))
+		}
+	}
+	assert.Equal(t, []string{"fe80::1%eth0", "fe80::2%eth0", "fe80::1%eth1"}, out)
+	assert.Equal(t, net.IPv6len, len(z.Zone("eth0").GetIPs(1)[0]))
+}
@@ -0,0 +1,106 @@
+package netaddr
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// WriteReverseZone writes BIND style reverse zone content with a PTR record
+// for every address in the set. The host name for each address comes from
+// template where {a}, {b}, {c} and {d} are replaced with the octets of the
+// address, e.g. "host-{a}-{b}-{c}-{d}.example.net." The records are grouped
+// under an $ORIGIN line for each /24 zone, which is where they must be served
+// from unless a classless zone named by ReverseZones has been delegated with
+// CNAMEs as described in RFC 2317. If generate is true, a single $GENERATE
+// directive is written for each contiguous block of two or more addresses
+// within a /24 instead of one line per address. No SOA or NS records are
+// written so the content is meant to be included in a zone file.
+//
+// Only IPv4 is supported. A set containing IPv6 results in an error wrapping
+// ErrFamilyMismatch and nothing is written.
+func WriteReverseZone(w io.Writer, set *IPSet, template string, generate bool) error {
+	if err := checkReverseTemplate(template); err != nil {
+		return err
+	}
+	ranges := set.ranges()
+	for _, r := range ranges {
+		if len(r.First) != net.IPv4len {
+			return fmt.Errorf("reverse zone for %s: %w", r, ErrFamilyMismatch)
+		}
+	}
+
+	zw := &zoneWriter{w: w}
+	origin := ""
+	for _, r := range ranges {
+		// Split the range at /24 boundaries
+		for first := r.First; ; {
+			last := IPv4(first[0], first[1], first[2], 255)
+			last = IPMin(last, r.Last)
+			if zone := reverseZone(first, 24); zone != origin {
+				origin = zone
+				zw.printf("$ORIGIN %s\n", origin)
+			}
+			zw.writeBlock(first, last, template, generate)
+			if last.Equal(r.Last) {
+				break
+			}
+			first = incrementIP(last)
+		}
+	}
+	return zw.err
+}
+
+// WriteReverseZoneNet is like WriteReverseZone for a single network
+func WriteReverseZoneNet(w io.Writer, n *net.IPNet, template string, generate bool) error {
+	set := &IPSet{}
+	set.InsertNet(n)
+	return WriteReverseZone(w, set, template, generate)
+}
+
+// checkReverseTemplate returns an error if the template has any placeholders
+// other than {a}, {b}, {c} and {d}
+func checkReverseTemplate(template string) error {
+	rest := strings.NewReplacer("{a}", "", "{b}", "", "{c}", "", "{d}", "").Replace(template)
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("bad placeholder in host name template %q", template)
+	}
+	return nil
+}
+
+// zoneWriter writes zone file lines and remembers the first error
+type zoneWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (zw *zoneWriter) printf(format string, args ...interface{}) {
+	if zw.err == nil {
+		_, zw.err = fmt.Fprintf(zw.w, format, args...)
+	}
+}
+
+// writeBlock writes the records for first through last which must differ only
+// in the last octet. A single address is always written as a plain record.
+func (zw *zoneWriter) writeBlock(first, last net.IP, template string, generate bool) {
+	name := func(template, d string) string {
+		return strings.NewReplacer(
+			"{a}", strconv.Itoa(int(first[0])),
+			"{b}", strconv.Itoa(int(first[1])),
+			"{c}", strconv.Itoa(int(first[2])),
+			"{d}", d,
+		).Replace(template)
+	}
+	if generate && first[3] != last[3] {
+		// $GENERATE replaces $ with the last octet so literal ones in the
+		// template must be escaped
+		escaped := strings.Replace(template, "$", "\\$", -1)
+		zw.printf("$GENERATE %d-%d $ IN PTR %s\n", first[3], last[3], name(escaped, "$"))
+		return
+	}
+	for d := int(first[3]); d <= int(last[3]); d++ {
+		zw.printf("%d IN PTR %s\n", d, name(template, strconv.Itoa(d)))
+	}
+}
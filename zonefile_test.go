@@ -0,0 +1,101 @@
+package netaddr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTemplate = "host-{a}-{b}-{c}-{d}.example.net."
+
+func TestWriteReverseZoneNet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteReverseZoneNet(buf, parse("192.0.2.0/30"), testTemplate, false))
+	assert.Equal(t, ""+
+		"$ORIGIN 2.0.192.in-addr.arpa.\n"+
+		"0 IN PTR host-192-0-2-0.example.net.\n"+
+		"1 IN PTR host-192-0-2-1.example.net.\n"+
+		"2 IN PTR host-192-0-2-2.example.net.\n"+
+		"3 IN PTR host-192-0-2-3.example.net.\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteReverseZoneNet(buf, parse("192.0.2.0/23"), testTemplate, true))
+	assert.Equal(t, ""+
+		"$ORIGIN 2.0.192.in-addr.arpa.\n"+
+		"$GENERATE 0-255 $ IN PTR host-192-0-2-$.example.net.\n"+
+		"$ORIGIN 3.0.192.in-addr.arpa.\n"+
+		"$GENERATE 0-255 $ IN PTR host-192-0-3-$.example.net.\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteReverseZoneNet(buf, parse("192.0.2.64/26"), "h$-{d}.{c}.example.net.", true))
+	assert.Equal(t, ""+
+		"$ORIGIN 2.0.192.in-addr.arpa.\n"+
+		"$GENERATE 64-127 $ IN PTR h\\$-$.2.example.net.\n", buf.String())
+}
+
+func TestWriteReverseZone(t *testing.T) {
+	set := &IPSet{}
+	set.InsertNet(parse("198.51.100.0/24"))
+	set.InsertNet(parse("192.0.2.0/31"))
+	set.Insert(ParseIP("192.0.2.7"))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteReverseZone(buf, set, testTemplate, true))
+	assert.Equal(t, ""+
+		"$ORIGIN 2.0.192.in-addr.arpa.\n"+
+		"$GENERATE 0-1 $ IN PTR host-192-0-2-$.example.net.\n"+
+		"7 IN PTR host-192-0-2-7.example.net.\n"+
+		"$ORIGIN 100.51.198.in-addr.arpa.\n"+
+		"$GENERATE 0-255 $ IN PTR host-198-51-100-$.example.net.\n", buf.String())
+
+	// A block which isn't a CIDR is still a single $GENERATE in one zone
+	set = &IPSet{}
+	for _, n := range rng("10.0.0.1-10.0.0.10").CIDRs() {
+		set.InsertNet(n)
+	}
+	buf.Reset()
+	assert.Nil(t, WriteReverseZone(buf, set, testTemplate, true))
+	assert.Equal(t, ""+
+		"$ORIGIN 0.0.10.in-addr.arpa.\n"+
+		"$GENERATE 1-10 $ IN PTR host-10-0-0-$.example.net.\n", buf.String())
+
+	// Blocks are split where they cross into the next /24
+	set = &IPSet{}
+	for _, n := range rng("10.0.0.254-10.0.1.1").CIDRs() {
+		set.InsertNet(n)
+	}
+	buf.Reset()
+	assert.Nil(t, WriteReverseZone(buf, set, testTemplate, false))
+	assert.Equal(t, ""+
+		"$ORIGIN 0.0.10.in-addr.arpa.\n"+
+		"254 IN PTR host-10-0-0-254.example.net.\n"+
+		"255 IN PTR host-10-0-0-255.example.net.\n"+
+		"$ORIGIN 1.0.10.in-addr.arpa.\n"+
+		"0 IN PTR host-10-0-1-0.example.net.\n"+
+		"1 IN PTR host-10-0-1-1.example.net.\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteReverseZone(buf, &IPSet{}, testTemplate, true))
+	assert.Equal(t, "", buf.String())
+}
+
+func TestWriteReverseZoneErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteReverseZoneNet(buf, parse("2001:db8::/64"), testTemplate, true)
+	assert.True(t, errors.Is(err, ErrFamilyMismatch))
+	assert.Equal(t, "", buf.String())
+
+	assert.NotNil(t, WriteReverseZoneNet(buf, parse("192.0.2.0/24"), "host-{e}.example.net.", true))
+	assert.NotNil(t, WriteReverseZoneNet(buf, parse("192.0.2.0/24"), "host-{d.example.net.", true))
+	assert.Equal(t, "", buf.String())
+
+	assert.Equal(t, errors.New("write failed"), WriteReverseZoneNet(failingWriter{}, parse("192.0.2.0/24"), testTemplate, false))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}